@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alde/yawi/pkg/providers"
+	"github.com/spf13/cobra"
+)
+
+var gnomeCmd = &cobra.Command{
+	Use:   "gnome",
+	Short: "Manage yawi's GNOME Shell extension",
+	Long: `GNOME on Wayland only shares window information with extensions. yawi ships
+its own small extension exposing the focused window, the window list, workspaces
+and focus changes over D-Bus.`,
+}
+
+var gnomeInstallCmd = &cobra.Command{
+	Use:   "install-extension",
+	Short: "Install (or update) yawi's GNOME Shell extension for the current user",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := providers.InstallGNOMEExtension()
+		if err != nil {
+			return fmt.Errorf("failed to install extension: %w", err)
+		}
+
+		fmt.Printf("Installed the yawi extension to %s\n", path)
+		fmt.Println("GNOME Shell only picks up new extensions on login, so log out and back in, then run:")
+		fmt.Printf("  gnome-extensions enable %s\n", providers.GNOMEExtensionUUID)
+		return nil
+	},
+}
+
+var gnomeStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether yawi's GNOME Shell extension is installed, enabled and running",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		status, err := (&providers.GNOMEProvider{}).ExtensionStatus()
+		if status == nil {
+			return err
+		}
+
+		fmt.Printf("Extension: %s\n", providers.GNOMEExtensionUUID)
+		switch {
+		case !status.Installed:
+			fmt.Println("Installed: no (run 'yawi gnome install-extension')")
+		case status.InstalledVersion < status.BundledVersion:
+			fmt.Printf("Installed: version %d at %s, this yawi bundles version %d (run 'yawi gnome install-extension' to update)\n",
+				status.InstalledVersion, status.Path, status.BundledVersion)
+		default:
+			fmt.Printf("Installed: version %d at %s\n", status.InstalledVersion, status.Path)
+		}
+
+		if err != nil {
+			fmt.Printf("GNOME Shell: not reachable (%v)\n", err)
+			return nil
+		}
+
+		fmt.Printf("Enabled: %s\n", yesNo(status.Enabled))
+		if status.State != "" {
+			fmt.Printf("State: %s\n", status.State)
+		}
+		if status.Error != "" {
+			fmt.Printf("Error: %s\n", status.Error)
+		}
+		fmt.Printf("Running: %s\n", yesNo(status.Running))
+		return nil
+	},
+}
+
+// yesNo formats a bool for humans
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func init() {
+	gnomeCmd.AddCommand(gnomeInstallCmd)
+	gnomeCmd.AddCommand(gnomeStatusCmd)
+}
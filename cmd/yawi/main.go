@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
 
-	"github.com/spf13/cobra"
 	"github.com/alde/yawi/pkg/compositor"
 	"github.com/alde/yawi/pkg/providers"
+	"github.com/alde/yawi/pkg/window"
+	"github.com/spf13/cobra"
 )
 
 var (
@@ -28,14 +33,10 @@ var rootCmd = &cobra.Command{
 across different platforms and window managers. By default, it outputs just the
 window class name, making it perfect for use in scripts and automation.
 
-Supported platforms: Hyprland, Sway, GNOME Shell (Linux), macOS`,
+Supported platforms: Hyprland, Sway, i3, GNOME Shell, KDE Plasma, wlroots compositors,
+EWMH X11 window managers (Linux), macOS`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		comp := compositor.Detect()
-		if comp == compositor.Unknown {
-			return fmt.Errorf("unable to detect supported platform\nSupported: Hyprland, Sway, GNOME Shell (Linux), macOS")
-		}
-
-		provider, err := providers.NewProvider(comp)
+		provider, err := detectProvider()
 		if err != nil {
 			return err
 		}
@@ -69,6 +70,20 @@ var compositorCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		comp := compositor.Detect()
 		fmt.Printf("Current compositor: %s\n", comp)
+		if comp == compositor.Unknown {
+			return nil
+		}
+
+		// The version is a nice-to-have; detection alone still answers the question
+		provider, err := providers.NewProvider(comp)
+		if err != nil {
+			return nil
+		}
+		if versioner, ok := provider.(window.Versioner); ok {
+			if version, err := versioner.CompositorVersion(); err == nil {
+				fmt.Printf("Version: %s\n", version)
+			}
+		}
 		return nil
 	},
 }
@@ -77,12 +92,7 @@ var infoCmd = &cobra.Command{
 	Use:   "info",
 	Short: "Show full window information as JSON",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		comp := compositor.Detect()
-		if comp == compositor.Unknown {
-			return fmt.Errorf("unable to detect supported platform\nSupported: Hyprland, Sway, GNOME Shell (Linux), macOS")
-		}
-
-		provider, err := providers.NewProvider(comp)
+		provider, err := detectProvider()
 		if err != nil {
 			return err
 		}
@@ -92,12 +102,96 @@ var infoCmd = &cobra.Command{
 			return fmt.Errorf("failed to get active window: %w", err)
 		}
 
-		jsonData, err := json.MarshalIndent(windowInfo, "", "  ")
+		return printJSON(windowInfo)
+	},
+}
+
+var listWorkspace string
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all open windows as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		lister, ok := provider.(window.Lister)
+		if !ok {
+			return fmt.Errorf("listing windows is not supported on %s", provider.Name())
+		}
+
+		windows, err := lister.ListWindows()
 		if err != nil {
-			return fmt.Errorf("failed to create JSON output: %w", err)
+			return fmt.Errorf("failed to list windows: %w", err)
 		}
-		fmt.Println(string(jsonData))
 
+		if listWorkspace != "" {
+			filtered := make([]*window.WindowInfo, 0, len(windows))
+			for _, w := range windows {
+				if w.Workspace == listWorkspace {
+					filtered = append(filtered, w)
+				}
+			}
+			windows = filtered
+		}
+
+		return printJSON(windows)
+	},
+}
+
+var watchJSON bool
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print the active window every time it changes",
+	Long: `Watch keeps running and prints a line whenever the active window changes.
+By default each line is the window class; use --json for one JSON object per line,
+which also carries the active binding mode (Sway) or submap (Hyprland).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		watcher, ok := provider.(window.Watcher)
+		if !ok {
+			return fmt.Errorf("watching is not supported on %s", provider.Name())
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		events, err := watcher.Watch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to watch active window: %w", err)
+		}
+
+		var last *window.Event
+		for event := range events {
+			if watchJSON {
+				jsonData, err := json.Marshal(event)
+				if err != nil {
+					return fmt.Errorf("failed to create JSON output: %w", err)
+				}
+				fmt.Println(string(jsonData))
+				continue
+			}
+
+			// The plain output only shows the window, so skip mode-only changes
+			windowChanged := last == nil || !reflect.DeepEqual(last.Window, event.Window)
+			last = &event
+			if !windowChanged {
+				continue
+			}
+
+			class := ""
+			if event.Window != nil {
+				class = event.Window.Class
+			}
+			fmt.Println(class)
+		}
 		return nil
 	},
 }
@@ -110,16 +204,37 @@ var versionCmd = &cobra.Command{
 	},
 }
 
+// detectProvider returns the provider for the compositor we're running under
+func detectProvider() (window.Provider, error) {
+	comp := compositor.Detect()
+	if comp == compositor.Unknown {
+		return nil, fmt.Errorf("unable to detect supported platform\nSupported: Hyprland, Sway, i3, GNOME Shell, KDE Plasma, wlroots compositors, EWMH X11 window managers (Linux), macOS")
+	}
+	return providers.NewProvider(comp)
+}
+
 func init() {
 	// Disable default completion command since it might confuse users
 	rootCmd.CompletionOptions.DisableDefaultCmd = true
-	
+
 	// Set up suggestion function for unknown commands
 	rootCmd.SuggestionsMinimumDistance = 1
 	rootCmd.SuggestFor = []string{"ful", "josn", "jsn", "inf", "vers"}
-	
+
 	// Add subcommands
 	rootCmd.AddCommand(compositorCmd)
-	rootCmd.AddCommand(infoCmd) 
+	rootCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(workspacesCmd)
+	rootCmd.AddCommand(activitiesCmd)
+	rootCmd.AddCommand(monitorsCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(windowCmd)
+	rootCmd.AddCommand(markCmd)
+	rootCmd.AddCommand(modeCmd)
+	rootCmd.AddCommand(gnomeCmd)
 	rootCmd.AddCommand(versionCmd)
-}
\ No newline at end of file
+
+	listCmd.Flags().StringVarP(&listWorkspace, "workspace", "w", "", "only list windows on this workspace")
+	watchCmd.Flags().BoolVar(&watchJSON, "json", false, "print each change as a JSON object")
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/spf13/cobra"
+)
+
+var markCmd = &cobra.Command{
+	Use:   "mark",
+	Short: "List, set and jump to window marks",
+	Long: `Marks are named bookmarks for windows. Without a subcommand, every mark
+currently set is printed, one per line.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		marker, err := detectMarker()
+		if err != nil {
+			return err
+		}
+
+		marks, err := marker.ListMarks()
+		if err != nil {
+			return fmt.Errorf("failed to list marks: %w", err)
+		}
+		for _, mark := range marks {
+			fmt.Println(mark)
+		}
+		return nil
+	},
+}
+
+var markAddCmd = &cobra.Command{
+	Use:   "add <mark> [id]",
+	Short: "Mark a window",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mark := args[0]
+		return withController(args[1:], func(c window.Controller, id string) error {
+			marker, ok := c.(window.Marker)
+			if !ok {
+				return fmt.Errorf("marks are not supported here")
+			}
+			return marker.AddMark(id, mark)
+		})
+	},
+}
+
+var markRemoveCmd = &cobra.Command{
+	Use:   "remove <mark> [id]",
+	Short: "Remove a mark from a window",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mark := args[0]
+		return withController(args[1:], func(c window.Controller, id string) error {
+			marker, ok := c.(window.Marker)
+			if !ok {
+				return fmt.Errorf("marks are not supported here")
+			}
+			return marker.RemoveMark(id, mark)
+		})
+	},
+}
+
+var markFocusCmd = &cobra.Command{
+	Use:   "focus <mark>",
+	Short: "Focus the window carrying a mark",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		marker, err := detectMarker()
+		if err != nil {
+			return err
+		}
+		return marker.FocusMark(args[0])
+	},
+}
+
+// detectMarker returns the current provider if it supports marks
+func detectMarker() (window.Marker, error) {
+	provider, err := detectProvider()
+	if err != nil {
+		return nil, err
+	}
+
+	marker, ok := provider.(window.Marker)
+	if !ok {
+		return nil, fmt.Errorf("marks are not supported on %s", provider.Name())
+	}
+	return marker, nil
+}
+
+func init() {
+	markCmd.AddCommand(markAddCmd)
+	markCmd.AddCommand(markRemoveCmd)
+	markCmd.AddCommand(markFocusCmd)
+}
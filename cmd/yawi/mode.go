@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/spf13/cobra"
+)
+
+var modeCmd = &cobra.Command{
+	Use:   "mode",
+	Short: "Show the active binding mode (Sway, i3) or submap (Hyprland)",
+	Long: `Mode prints the name of the active keybinding mode, "default" when no
+other mode is active. Use 'yawi watch --json' to follow mode changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		reporter, ok := provider.(window.ModeReporter)
+		if !ok {
+			return fmt.Errorf("binding modes are not supported on %s", provider.Name())
+		}
+
+		mode, err := reporter.BindingMode()
+		if err != nil {
+			return fmt.Errorf("failed to get binding mode: %w", err)
+		}
+		fmt.Println(mode)
+		return nil
+	},
+}
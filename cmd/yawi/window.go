@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/spf13/cobra"
+)
+
+var windowCmd = &cobra.Command{
+	Use:   "window",
+	Short: "Act on windows (focus, close, move, ...)",
+	Long: `Window actions take an optional window ID as reported by 'yawi info' or
+'yawi list'. When the ID is left out, the currently active window is used.
+
+On Sway and i3, windows can also be picked by --app-id, --class or --title
+(regular expressions), or by passing criteria such as '[app_id="foot"]' as the ID.`,
+}
+
+var windowMatch struct {
+	appID string
+	class string
+	title string
+}
+
+var windowFocusCmd = &cobra.Command{
+	Use:   "focus [id]",
+	Short: "Focus a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			return c.FocusWindow(id)
+		})
+	},
+}
+
+var windowCloseCmd = &cobra.Command{
+	Use:   "close [id]",
+	Short: "Close a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			return c.CloseWindow(id)
+		})
+	},
+}
+
+var windowMoveSilent bool
+
+var windowMoveCmd = &cobra.Command{
+	Use:   "move <workspace> [id]",
+	Short: "Move a window to another workspace",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workspace := args[0]
+		return withController(args[1:], func(c window.Controller, id string) error {
+			if !windowMoveSilent {
+				return c.MoveWindowToWorkspace(id, workspace)
+			}
+			mover, ok := c.(window.SilentMover)
+			if !ok {
+				return fmt.Errorf("silent moves are not supported here")
+			}
+			return mover.MoveWindowToWorkspaceSilent(id, workspace)
+		})
+	},
+}
+
+var windowFloatCmd = &cobra.Command{
+	Use:   "float [id]",
+	Short: "Toggle floating for a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			return c.ToggleFloating(id)
+		})
+	},
+}
+
+var windowFullscreenCmd = &cobra.Command{
+	Use:   "fullscreen [id]",
+	Short: "Toggle fullscreen for a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			return c.ToggleFullscreen(id)
+		})
+	},
+}
+
+var windowOutputCmd = &cobra.Command{
+	Use:   "output <output> [id]",
+	Short: "Move a window to another monitor",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output := args[0]
+		return withController(args[1:], func(c window.Controller, id string) error {
+			mover, ok := c.(window.OutputMover)
+			if !ok {
+				return fmt.Errorf("moving windows between monitors is not supported here")
+			}
+			return mover.MoveWindowToOutput(id, output)
+		})
+	},
+}
+
+var windowScratchpadCmd = &cobra.Command{
+	Use:   "scratchpad [id]",
+	Short: "Show or hide a scratchpad window (cycles the scratchpad without an ID)",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		scratchpad, ok := provider.(window.Scratchpad)
+		if !ok {
+			return fmt.Errorf("the scratchpad is not supported on %s", provider.Name())
+		}
+
+		id := ""
+		if len(args) > 0 {
+			id = args[0]
+		} else if id, err = matchedWindow(provider); err != nil {
+			return err
+		}
+		return scratchpad.ShowScratchpad(id)
+	},
+}
+
+var windowPinCmd = &cobra.Command{
+	Use:   "pin [id]",
+	Short: "Toggle pinning a window to all workspaces",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			pinner, ok := c.(window.Pinner)
+			if !ok {
+				return fmt.Errorf("pinning is not supported here")
+			}
+			return pinner.TogglePin(id)
+		})
+	},
+}
+
+var windowMinimizeCmd = &cobra.Command{
+	Use:   "minimize [id]",
+	Short: "Minimize a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			minimizer, ok := c.(window.Minimizer)
+			if !ok {
+				return fmt.Errorf("minimizing is not supported here")
+			}
+			return minimizer.MinimizeWindow(id)
+		})
+	},
+}
+
+var windowMaximizeCmd = &cobra.Command{
+	Use:   "maximize [id]",
+	Short: "Toggle maximizing a window",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withController(args, func(c window.Controller, id string) error {
+			maximizer, ok := c.(window.Maximizer)
+			if !ok {
+				return fmt.Errorf("maximizing is not supported here")
+			}
+			return maximizer.ToggleMaximize(id)
+		})
+	},
+}
+
+// withController resolves the provider and target window, then runs action on them
+func withController(args []string, action func(c window.Controller, id string) error) error {
+	provider, err := detectProvider()
+	if err != nil {
+		return err
+	}
+
+	controller, ok := provider.(window.Controller)
+	if !ok {
+		return fmt.Errorf("window actions are not supported on %s", provider.Name())
+	}
+
+	id, err := matchedWindow(provider)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case id != "":
+		if len(args) > 0 {
+			return fmt.Errorf("give either a window ID or --app-id/--class/--title, not both")
+		}
+	case len(args) > 0:
+		id = args[0]
+	default:
+		active, err := provider.GetActiveWindow()
+		if err != nil {
+			return fmt.Errorf("failed to get active window: %w", err)
+		}
+		id = active.ID
+		if id == "" {
+			return fmt.Errorf("the active window has no ID on %s", provider.Name())
+		}
+	}
+
+	return action(controller, id)
+}
+
+// matchedWindow turns the --app-id/--class/--title flags into a window
+// selector, returning "" when none of them were given
+func matchedWindow(provider window.Provider) (string, error) {
+	if windowMatch.appID == "" && windowMatch.class == "" && windowMatch.title == "" {
+		return "", nil
+	}
+
+	selector, ok := provider.(window.Selector)
+	if !ok {
+		return "", fmt.Errorf("selecting windows by --app-id/--class/--title is not supported on %s", provider.Name())
+	}
+	return selector.SelectWindows(windowMatch.appID, windowMatch.class, windowMatch.title)
+}
+
+func init() {
+	windowCmd.PersistentFlags().StringVar(&windowMatch.appID, "app-id", "", "select windows whose app_id matches this regex")
+	windowCmd.PersistentFlags().StringVar(&windowMatch.class, "class", "", "select windows whose class matches this regex")
+	windowCmd.PersistentFlags().StringVar(&windowMatch.title, "title", "", "select windows whose title matches this regex")
+
+	windowMoveCmd.Flags().BoolVarP(&windowMoveSilent, "silent", "s", false, "don't follow the window to its new workspace")
+
+	windowCmd.AddCommand(windowFocusCmd)
+	windowCmd.AddCommand(windowCloseCmd)
+	windowCmd.AddCommand(windowMoveCmd)
+	windowCmd.AddCommand(windowOutputCmd)
+	windowCmd.AddCommand(windowScratchpadCmd)
+	windowCmd.AddCommand(windowFloatCmd)
+	windowCmd.AddCommand(windowFullscreenCmd)
+	windowCmd.AddCommand(windowPinCmd)
+	windowCmd.AddCommand(windowMinimizeCmd)
+	windowCmd.AddCommand(windowMaximizeCmd)
+}
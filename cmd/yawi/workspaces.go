@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/spf13/cobra"
+)
+
+var workspacesActive bool
+
+var workspacesCmd = &cobra.Command{
+	Use:   "workspaces",
+	Short: "List workspaces as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		lister, ok := provider.(window.WorkspaceLister)
+		if !ok {
+			return fmt.Errorf("listing workspaces is not supported on %s", provider.Name())
+		}
+
+		var result any
+		if workspacesActive {
+			result, err = lister.ActiveWorkspace()
+		} else {
+			result, err = lister.ListWorkspaces()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get workspaces: %w", err)
+		}
+
+		return printJSON(result)
+	},
+}
+
+var activitiesCurrent bool
+
+var activitiesCmd = &cobra.Command{
+	Use:   "activities",
+	Short: "List KDE Plasma Activities as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		lister, ok := provider.(window.ActivityLister)
+		if !ok {
+			return fmt.Errorf("listing Activities is not supported on %s", provider.Name())
+		}
+
+		var result any
+		if activitiesCurrent {
+			result, err = lister.CurrentActivity()
+		} else {
+			result, err = lister.ListActivities()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get Activities: %w", err)
+		}
+
+		return printJSON(result)
+	},
+}
+
+var monitorsCmd = &cobra.Command{
+	Use:   "monitors",
+	Short: "List monitors as JSON",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := detectProvider()
+		if err != nil {
+			return err
+		}
+
+		lister, ok := provider.(window.MonitorLister)
+		if !ok {
+			return fmt.Errorf("listing monitors is not supported on %s", provider.Name())
+		}
+
+		monitors, err := lister.ListMonitors()
+		if err != nil {
+			return fmt.Errorf("failed to get monitors: %w", err)
+		}
+
+		return printJSON(monitors)
+	},
+}
+
+// printJSON writes v as indented JSON to stdout
+func printJSON(v any) error {
+	jsonData, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to create JSON output: %w", err)
+	}
+	fmt.Println(string(jsonData))
+	return nil
+}
+
+func init() {
+	workspacesCmd.Flags().BoolVarP(&workspacesActive, "active", "a", false, "only show the focused workspace")
+	activitiesCmd.Flags().BoolVarP(&activitiesCurrent, "current", "c", false, "only show the current Activity")
+}
@@ -4,6 +4,7 @@ go 1.25
 
 require (
 	github.com/godbus/dbus/v5 v5.1.0
+	github.com/jezek/xgb v1.1.1
 	github.com/spf13/cobra v1.10.1
 )
 
@@ -13,7 +13,11 @@ const (
 	Unknown Type = iota
 	Hyprland
 	Sway
+	I3
 	GNOME
+	KDE
+	X11
+	Wlroots
 	MacOS
 )
 
@@ -23,8 +27,16 @@ func (c Type) String() string {
 		return "Hyprland"
 	case Sway:
 		return "Sway"
+	case I3:
+		return "i3"
 	case GNOME:
 		return "GNOME"
+	case KDE:
+		return "KDE"
+	case X11:
+		return "X11"
+	case Wlroots:
+		return "wlroots"
 	case MacOS:
 		return "macOS"
 	default:
@@ -50,13 +62,35 @@ func Detect() Type {
 		return Sway
 	}
 
-	// GNOME can be detected through desktop environment variables
+	// Desktop environments announce themselves through these variables
 	desktop := strings.ToLower(os.Getenv("XDG_CURRENT_DESKTOP"))
 	session := strings.ToLower(os.Getenv("XDG_SESSION_DESKTOP"))
 
+	// i3 exports I3SOCK like Sway does, but never SWAYSOCK
+	if i3Socket := os.Getenv("I3SOCK"); i3Socket != "" || desktop == "i3" || session == "i3" {
+		return I3
+	}
+
+	// GNOME can be detected through desktop environment variables
 	if strings.Contains(desktop, "gnome") || strings.Contains(session, "gnome") {
 		return GNOME
 	}
 
+	// Plasma sets XDG_CURRENT_DESKTOP=KDE; KDE_FULL_SESSION predates it
+	if strings.Contains(desktop, "kde") || strings.Contains(session, "plasma") || os.Getenv("KDE_FULL_SESSION") == "true" {
+		return KDE
+	}
+
+	// Other Wayland compositors are asked through the foreign toplevel
+	// protocols. They export DISPLAY for XWayland too, so check them first.
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		return Wlroots
+	}
+
+	// Any other X session is left to the EWMH fallback
+	if os.Getenv("DISPLAY") != "" {
+		return X11
+	}
+
 	return Unknown
-}
\ No newline at end of file
+}
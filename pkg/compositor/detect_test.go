@@ -11,8 +11,12 @@ func TestDetect(t *testing.T) {
 	originalVars := map[string]string{
 		"HYPRLAND_INSTANCE_SIGNATURE": os.Getenv("HYPRLAND_INSTANCE_SIGNATURE"),
 		"SWAYSOCK":                    os.Getenv("SWAYSOCK"),
+		"I3SOCK":                      os.Getenv("I3SOCK"),
 		"XDG_CURRENT_DESKTOP":         os.Getenv("XDG_CURRENT_DESKTOP"),
 		"XDG_SESSION_DESKTOP":         os.Getenv("XDG_SESSION_DESKTOP"),
+		"KDE_FULL_SESSION":            os.Getenv("KDE_FULL_SESSION"),
+		"DISPLAY":                     os.Getenv("DISPLAY"),
+		"WAYLAND_DISPLAY":             os.Getenv("WAYLAND_DISPLAY"),
 	}
 
 	// Clean up after test
@@ -36,6 +40,7 @@ func TestDetect(t *testing.T) {
 			envVars: map[string]string{
 				"HYPRLAND_INSTANCE_SIGNATURE": "some-signature",
 				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
 				"XDG_CURRENT_DESKTOP":         "",
 				"XDG_SESSION_DESKTOP":         "",
 			},
@@ -46,16 +51,40 @@ func TestDetect(t *testing.T) {
 			envVars: map[string]string{
 				"HYPRLAND_INSTANCE_SIGNATURE": "",
 				"SWAYSOCK":                    "/run/user/1000/sway-ipc.sock",
+				"I3SOCK":                      "/run/user/1000/sway-ipc.sock",
 				"XDG_CURRENT_DESKTOP":         "",
 				"XDG_SESSION_DESKTOP":         "",
 			},
 			expected: Sway,
 		},
+		{
+			name: "i3 detection via I3SOCK",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "/run/user/1000/i3/ipc-socket.1234",
+				"XDG_CURRENT_DESKTOP":         "",
+				"XDG_SESSION_DESKTOP":         "",
+			},
+			expected: I3,
+		},
+		{
+			name: "i3 detection via XDG_CURRENT_DESKTOP",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
+				"XDG_CURRENT_DESKTOP":         "i3",
+				"XDG_SESSION_DESKTOP":         "",
+			},
+			expected: I3,
+		},
 		{
 			name: "GNOME detection via XDG_CURRENT_DESKTOP",
 			envVars: map[string]string{
 				"HYPRLAND_INSTANCE_SIGNATURE": "",
 				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
 				"XDG_CURRENT_DESKTOP":         "GNOME",
 				"XDG_SESSION_DESKTOP":         "",
 			},
@@ -66,18 +95,75 @@ func TestDetect(t *testing.T) {
 			envVars: map[string]string{
 				"HYPRLAND_INSTANCE_SIGNATURE": "",
 				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
 				"XDG_CURRENT_DESKTOP":         "",
 				"XDG_SESSION_DESKTOP":         "gnome",
 			},
 			expected: GNOME,
 		},
+		{
+			name: "KDE detection via XDG_CURRENT_DESKTOP",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
+				"XDG_CURRENT_DESKTOP":         "KDE",
+				"XDG_SESSION_DESKTOP":         "",
+				"KDE_FULL_SESSION":            "",
+			},
+			expected: KDE,
+		},
+		{
+			name: "KDE detection via KDE_FULL_SESSION",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
+				"XDG_CURRENT_DESKTOP":         "",
+				"XDG_SESSION_DESKTOP":         "",
+				"KDE_FULL_SESSION":            "true",
+			},
+			expected: KDE,
+		},
+		{
+			name: "X11 fallback via DISPLAY",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
+				"XDG_CURRENT_DESKTOP":         "XFCE",
+				"XDG_SESSION_DESKTOP":         "",
+				"KDE_FULL_SESSION":            "",
+				"DISPLAY":                     ":0",
+				"WAYLAND_DISPLAY":             "",
+			},
+			expected: X11,
+		},
+		{
+			name: "Other Wayland compositors despite XWayland's DISPLAY",
+			envVars: map[string]string{
+				"HYPRLAND_INSTANCE_SIGNATURE": "",
+				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
+				"XDG_CURRENT_DESKTOP":         "",
+				"XDG_SESSION_DESKTOP":         "",
+				"KDE_FULL_SESSION":            "",
+				"DISPLAY":                     ":0",
+				"WAYLAND_DISPLAY":             "wayland-1",
+			},
+			expected: Wlroots,
+		},
 		{
 			name: "Unknown when no match",
 			envVars: map[string]string{
 				"HYPRLAND_INSTANCE_SIGNATURE": "",
 				"SWAYSOCK":                    "",
+				"I3SOCK":                      "",
 				"XDG_CURRENT_DESKTOP":         "unity",
 				"XDG_SESSION_DESKTOP":         "",
+				"KDE_FULL_SESSION":            "",
+				"DISPLAY":                     "",
+				"WAYLAND_DISPLAY":             "",
 			},
 			expected: func() Type {
 				if runtime.GOOS == "darwin" {
@@ -119,7 +205,11 @@ func TestType_String(t *testing.T) {
 	}{
 		{Hyprland, "Hyprland"},
 		{Sway, "Sway"},
+		{I3, "i3"},
 		{GNOME, "GNOME"},
+		{KDE, "KDE"},
+		{X11, "X11"},
+		{Wlroots, "wlroots"},
 		{MacOS, "macOS"},
 		{Unknown, "Unknown"},
 		{Type(999), "Unknown"}, // Invalid type
@@ -14,11 +14,19 @@ func NewProvider(comp compositor.Type) (window.Provider, error) {
 		return &HyprlandProvider{}, nil
 	case compositor.Sway:
 		return &SwayProvider{}, nil
+	case compositor.I3:
+		return NewI3Provider(), nil
 	case compositor.GNOME:
 		return &GNOMEProvider{}, nil
+	case compositor.KDE:
+		return &KDEProvider{}, nil
+	case compositor.X11:
+		return &X11Provider{}, nil
+	case compositor.Wlroots:
+		return &WlrootsProvider{}, nil
 	case compositor.MacOS:
 		return &MacOSProvider{}, nil
 	default:
-		return nil, fmt.Errorf("unsupported compositor: %s\nSupported: Hyprland, Sway, GNOME Shell, macOS", comp)
+		return nil, fmt.Errorf("unsupported compositor: %s\nSupported: Hyprland, Sway, i3, GNOME Shell, KDE Plasma, wlroots compositors, X11 (EWMH), macOS", comp)
 	}
-}
\ No newline at end of file
+}
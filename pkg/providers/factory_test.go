@@ -26,12 +26,36 @@ func TestNewProvider(t *testing.T) {
 			expectError:   false,
 			expectedType:  "*providers.SwayProvider",
 		},
+		{
+			name:          "i3 provider",
+			compositorType: compositor.I3,
+			expectError:   false,
+			expectedType:  "*providers.I3Provider",
+		},
 		{
 			name:          "GNOME provider",
 			compositorType: compositor.GNOME,
 			expectError:   false,
 			expectedType:  "*providers.GNOMEProvider",
 		},
+		{
+			name:          "KDE provider",
+			compositorType: compositor.KDE,
+			expectError:   false,
+			expectedType:  "*providers.KDEProvider",
+		},
+		{
+			name:          "X11 provider",
+			compositorType: compositor.X11,
+			expectError:   false,
+			expectedType:  "*providers.X11Provider",
+		},
+		{
+			name:          "wlroots provider",
+			compositorType: compositor.Wlroots,
+			expectError:   false,
+			expectedType:  "*providers.WlrootsProvider",
+		},
 		{
 			name:          "macOS provider",
 			compositorType: compositor.MacOS,
@@ -85,7 +109,11 @@ func TestProviderNames(t *testing.T) {
 	}{
 		{compositor.Hyprland, "Hyprland"},
 		{compositor.Sway, "Sway"},
+		{compositor.I3, "i3"},
 		{compositor.GNOME, "GNOME Shell"},
+		{compositor.KDE, "KDE Plasma"},
+		{compositor.X11, "X11"},
+		{compositor.Wlroots, "wlroots"},
 		{compositor.MacOS, "macOS"},
 	}
 
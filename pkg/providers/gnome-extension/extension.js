@@ -0,0 +1,190 @@
+// yawi GNOME Shell extension
+//
+// Exports org.gnome.Shell.Extensions.Yawi on the session bus so yawi can see
+// windows on GNOME Wayland, where Mutter doesn't share them with other clients.
+// Every window is described as JSON with the fields yawi's GNOME provider reads.
+
+import Gio from 'gi://Gio';
+import GLib from 'gi://GLib';
+import Meta from 'gi://Meta';
+import {Extension} from 'resource:///org/gnome/shell/extensions/extension.js';
+
+const OBJECT_PATH = '/org/gnome/Shell/Extensions/Yawi';
+
+const INTERFACE = `
+<node>
+  <interface name="org.gnome.Shell.Extensions.Yawi">
+    <method name="GetFocusedWindow">
+      <arg type="s" direction="out" name="window"/>
+    </method>
+    <method name="ListWindows">
+      <arg type="s" direction="out" name="windows"/>
+    </method>
+    <method name="GetWorkspaces">
+      <arg type="s" direction="out" name="workspaces"/>
+    </method>
+    <method name="Activate">
+      <arg type="t" direction="in" name="id"/>
+    </method>
+    <method name="Close">
+      <arg type="t" direction="in" name="id"/>
+    </method>
+    <method name="Minimize">
+      <arg type="t" direction="in" name="id"/>
+    </method>
+    <method name="ToggleMaximize">
+      <arg type="t" direction="in" name="id"/>
+    </method>
+    <method name="ToggleFullscreen">
+      <arg type="t" direction="in" name="id"/>
+    </method>
+    <method name="MoveToWorkspace">
+      <arg type="t" direction="in" name="id"/>
+      <arg type="u" direction="in" name="workspace"/>
+    </method>
+    <signal name="FocusChanged">
+      <arg type="s" name="window"/>
+    </signal>
+  </interface>
+</node>`;
+
+function describeWindow(win) {
+    if (!win)
+        return null;
+
+    const rect = win.get_frame_rect();
+    const workspace = win.get_workspace();
+    return {
+        id: win.get_id(),
+        title: win.get_title() ?? '',
+        wm_class: win.get_wm_class() ?? '',
+        wm_class_instance: win.get_wm_class_instance() ?? '',
+        pid: win.get_pid(),
+        focus: win.has_focus(),
+        // null when the window is on all workspaces
+        workspace: workspace ? workspace.index() : null,
+        monitor: win.get_monitor(),
+        x: rect.x,
+        y: rect.y,
+        width: rect.width,
+        height: rect.height,
+        maximized: win.get_maximized(),
+        in_current_workspace: win.located_on_workspace(global.workspace_manager.get_active_workspace()),
+    };
+}
+
+function findWindow(id) {
+    const win = global.get_window_actors()
+        .map(actor => actor.meta_window)
+        .find(w => w.get_id() === id);
+    if (!win)
+        throw new Error(`no window with ID ${id}`);
+    return win;
+}
+
+function normalWindows() {
+    return global.get_window_actors()
+        .map(actor => actor.meta_window)
+        .filter(win => win.get_window_type() === Meta.WindowType.NORMAL);
+}
+
+export default class YawiExtension extends Extension {
+    enable() {
+        this._dbus = Gio.DBusExportedObject.wrapJSObject(INTERFACE, this);
+        this._dbus.export(Gio.DBus.session, OBJECT_PATH);
+
+        this._focusedWindow = null;
+        this._titleId = 0;
+        this._focusId = global.display.connect('notify::focus-window', () => this._focusChanged());
+        this._workspaceId = global.workspace_manager.connect('active-workspace-changed', () => this._emitFocus());
+        this._focusChanged();
+    }
+
+    disable() {
+        global.display.disconnect(this._focusId);
+        global.workspace_manager.disconnect(this._workspaceId);
+        this._trackTitle(null);
+
+        this._dbus.flush();
+        this._dbus.unexport();
+        this._dbus = null;
+    }
+
+    GetFocusedWindow() {
+        return JSON.stringify(describeWindow(global.display.focus_window));
+    }
+
+    ListWindows() {
+        return JSON.stringify(normalWindows().map(describeWindow));
+    }
+
+    GetWorkspaces() {
+        const manager = global.workspace_manager;
+        const active = manager.get_active_workspace_index();
+        const workspaces = [];
+        for (let i = 0; i < manager.get_n_workspaces(); i++) {
+            const workspace = manager.get_workspace_by_index(i);
+            workspaces.push({
+                index: i,
+                active: i === active,
+                windows: workspace.list_windows().filter(win => win.get_window_type() === Meta.WindowType.NORMAL).length,
+            });
+        }
+        return JSON.stringify(workspaces);
+    }
+
+    Activate(id) {
+        const win = findWindow(id);
+        win.get_workspace()?.activate_with_focus(win, global.get_current_time());
+        win.activate(global.get_current_time());
+    }
+
+    Close(id) {
+        findWindow(id).delete(global.get_current_time());
+    }
+
+    Minimize(id) {
+        findWindow(id).minimize();
+    }
+
+    ToggleMaximize(id) {
+        const win = findWindow(id);
+        if (win.get_maximized() === Meta.MaximizeFlags.BOTH)
+            win.unmaximize(Meta.MaximizeFlags.BOTH);
+        else
+            win.maximize(Meta.MaximizeFlags.BOTH);
+    }
+
+    ToggleFullscreen(id) {
+        const win = findWindow(id);
+        if (win.is_fullscreen())
+            win.unmake_fullscreen();
+        else
+            win.make_fullscreen();
+    }
+
+    MoveToWorkspace(id, index) {
+        const manager = global.workspace_manager;
+        if (index >= manager.get_n_workspaces())
+            throw new Error(`no workspace ${index + 1}`);
+        findWindow(id).change_workspace_by_index(index, false);
+    }
+
+    _focusChanged() {
+        this._trackTitle(global.display.focus_window);
+        this._emitFocus();
+    }
+
+    // Title changes of the focused window are focus changes as far as yawi watch is concerned
+    _trackTitle(win) {
+        if (this._focusedWindow && this._titleId)
+            this._focusedWindow.disconnect(this._titleId);
+
+        this._focusedWindow = win;
+        this._titleId = win ? win.connect('notify::title', () => this._emitFocus()) : 0;
+    }
+
+    _emitFocus() {
+        this._dbus?.emit_signal('FocusChanged', new GLib.Variant('(s)', [this.GetFocusedWindow()]));
+    }
+}
@@ -3,13 +3,21 @@ package providers
 import (
 	"encoding/json"
 	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
 
-	"github.com/godbus/dbus/v5"
 	"github.com/alde/yawi/pkg/window"
 )
 
 // GNOMEProvider implements window information retrieval for GNOME Shell
-type GNOMEProvider struct{}
+type GNOMEProvider struct {
+	// bus opens the connection to GNOME Shell; nil means the D-Bus session bus
+	bus func() (gnomeBus, error)
+	// workspaceNames returns the configured workspace names; nil means gsettings
+	workspaceNames func() []string
+}
 
 // Name returns the provider name
 func (g *GNOMEProvider) Name() string {
@@ -18,70 +26,238 @@ func (g *GNOMEProvider) Name() string {
 
 // focusedWindowInfo represents the structure returned by the GNOME FocusedWindow extension
 type focusedWindowInfo struct {
-	Title              string   `json:"title,omitempty"`
-	WmClass            string   `json:"wm_class,omitempty"`
-	WmClassInstance    string   `json:"wm_class_instance,omitempty"`
-	Pid                int      `json:"pid,omitempty"`
-	Id                 int      `json:"id,omitempty"`
-	Width              int      `json:"width,omitempty"`
-	Height             int      `json:"height,omitempty"`
-	X                  int      `json:"x,omitempty"`
-	Y                  int      `json:"y,omitempty"`
-	Focus              bool     `json:"focus,omitempty"`
-	InCurrentWorkspace bool     `json:"in_current_workspace,omitempty"`
-	Moveable           bool     `json:"moveable,omitempty"`
-	Resizable          bool     `json:"resizeable,omitempty"`
-	CanClose           bool     `json:"canclose,omitempty"`
-	CanMaximize        bool     `json:"canmaximize,omitempty"`
-	Maximized          int      `json:"maximized,omitempty"`
-	CanMinimize        bool     `json:"canminimize,omitempty"`
-	Display            struct{} `json:"display,omitempty"`
-	FrameType          int      `json:"frame_type,omitempty"`
-	WindowType         int      `json:"window_type,omitempty"`
-	Layer              int      `json:"layer,omitempty"`
-	Monitor            int      `json:"monitor,omitempty"`
-	Role               *string  `json:"role,omitempty"`
-	Area               struct{} `json:"area,omitempty"`
-	AreaAll            struct{} `json:"area_all,omitempty"`
-	AreaCust           struct{} `json:"area_cust,omitempty"`
+	Title              string          `json:"title,omitempty"`
+	WmClass            string          `json:"wm_class,omitempty"`
+	WmClassInstance    string          `json:"wm_class_instance,omitempty"`
+	Pid                int             `json:"pid,omitempty"`
+	Id                 uint64          `json:"id,omitempty"`
+	Width              int             `json:"width,omitempty"`
+	Height             int             `json:"height,omitempty"`
+	X                  int             `json:"x,omitempty"`
+	Y                  int             `json:"y,omitempty"`
+	Focus              bool            `json:"focus,omitempty"`
+	InCurrentWorkspace bool            `json:"in_current_workspace,omitempty"`
+	Moveable           bool            `json:"moveable,omitempty"`
+	Resizable          bool            `json:"resizeable,omitempty"`
+	CanClose           bool            `json:"canclose,omitempty"`
+	CanMaximize        bool            `json:"canmaximize,omitempty"`
+	Maximized          flexInt         `json:"maximized,omitempty"`
+	CanMinimize        bool            `json:"canminimize,omitempty"`
+	Display            json.RawMessage `json:"display,omitempty"`
+	FrameType          int             `json:"frame_type,omitempty"`
+	WindowType         int             `json:"window_type,omitempty"`
+	Layer              int             `json:"layer,omitempty"`
+	Monitor            *int            `json:"monitor,omitempty"`
+	Workspace          *int            `json:"workspace,omitempty"`
+	Role               *string         `json:"role,omitempty"`
+	Area               json.RawMessage `json:"area,omitempty"`
+	AreaAll            json.RawMessage `json:"area_all,omitempty"`
+	AreaCust           json.RawMessage `json:"area_cust,omitempty"`
 }
 
-// GetActiveWindow retrieves the currently active window from GNOME Shell
+// GetActiveWindow retrieves the currently active window from GNOME Shell,
+// trying each backend in turn until one answers
 func (g *GNOMEProvider) GetActiveWindow() (*window.WindowInfo, error) {
-	conn, err := dbus.SessionBus()
+	bus, err := g.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to D-Bus session bus: %w", err)
+		return nil, err
+	}
+	defer bus.Close()
+
+	windowInfo, _, err := g.activeWindow(bus)
+	return windowInfo, err
+}
+
+// activeWindow runs the backend chain, also returning the backend that answered
+func (g *GNOMEProvider) activeWindow(bus gnomeBus) (*window.WindowInfo, *gnomeBackend, error) {
+	var failures []string
+	for i := range gnomeBackends {
+		backend := &gnomeBackends[i]
+		windowInfo, err := backend.activeWindow(bus)
+		if err == nil {
+			nameWorkspace(windowInfo, g.lookupWorkspaceNames)
+			return windowInfo, backend, nil
+		}
+		failures = append(failures, fmt.Sprintf("  %s: %v", backend.name, err))
+	}
+
+	return nil, nil, fmt.Errorf("unable to get GNOME active window - enable the Focused Window D-Bus or Window Calls extension\ntried:\n%s", strings.Join(failures, "\n"))
+}
+
+// ListWindows returns every window known to yawi's extension or, failing
+// that, the Window Calls extension
+func (g *GNOMEProvider) ListWindows() ([]*window.WindowInfo, error) {
+	bus, err := g.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	// Only ask gsettings once, and only if a backend reported a workspace
+	names := sync.OnceValue(g.lookupWorkspaceNames)
+
+	if list, err := yawiExtensionList(bus); err == nil {
+		windows := make([]*window.WindowInfo, 0, len(list))
+		for i := range list {
+			windowInfo := list[i].toWindowInfo()
+			nameWorkspace(windowInfo, names)
+			windows = append(windows, windowInfo)
+		}
+		return windows, nil
 	}
-	defer conn.Close()
 
-	// Try the FocusedWindow GNOME Shell extension
-	windowInfo, err := g.tryFocusedWindowExtension(conn)
-	if err == nil {
-		return windowInfo, nil
+	list, err := windowCallsList(bus)
+	if err != nil {
+		return nil, fmt.Errorf("listing windows needs yawi's or the Window Calls extension: %w", err)
 	}
 
-	return nil, fmt.Errorf("unable to get GNOME active window - make sure the Focused Window D-Bus extension is enabled")
+	windows := make([]*window.WindowInfo, 0, len(list))
+	for i := range list {
+		// Details knows more than List; fall back to the list entry if the window just went away
+		info := &list[i]
+		if details, err := windowCallsDetails(bus, info.Id); err == nil {
+			info = details
+		}
+
+		windowInfo := info.toWindowInfo()
+		nameWorkspace(windowInfo, names)
+		windows = append(windows, windowInfo)
+	}
+	return windows, nil
 }
 
-// tryFocusedWindowExtension attempts to get window info via the FocusedWindow GNOME extension
-func (g *GNOMEProvider) tryFocusedWindowExtension(conn *dbus.Conn) (*window.WindowInfo, error) {
-	obj := conn.Object("org.gnome.Shell", "/org/gnome/shell/extensions/FocusedWindow")
-	var result string
-	err := obj.Call("org.gnome.shell.extensions.FocusedWindow.Get", 0).Store(&result)
+// CompositorVersion returns the ShellVersion property of GNOME Shell
+func (g *GNOMEProvider) CompositorVersion() (string, error) {
+	bus, err := g.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to call FocusedWindow.Get D-Bus method: %w", err)
+		return "", err
+	}
+	defer bus.Close()
+
+	variant, err := bus.property(gnomeShellPath, "org.gnome.Shell.ShellVersion")
+	if err != nil {
+		return "", fmt.Errorf("failed to read GNOME Shell version: %w", err)
+	}
+
+	version, ok := variant.Value().(string)
+	if !ok {
+		return "", fmt.Errorf("unexpected GNOME Shell version type %s", variant.Signature())
+	}
+	return version, nil
+}
+
+// toWindowInfo converts the extension's JSON into the common window structure.
+// Workspaces are numbered from 1 like GNOME's own overview; the provider
+// swaps in configured names.
+func (info *focusedWindowInfo) toWindowInfo() *window.WindowInfo {
+	result := &window.WindowInfo{
+		Title:    info.Title,
+		Class:    info.WmClass,
+		Instance: info.WmClassInstance,
+		PID:      info.Pid,
+		GNOME: &window.GNOMEDetails{
+			WindowID:       info.Id,
+			WorkspaceIndex: info.Workspace,
+			MonitorIndex:   info.Monitor,
+		},
+	}
+
+	if info.Id != 0 {
+		result.ID = strconv.FormatUint(info.Id, 10)
 	}
+	if info.Workspace != nil && *info.Workspace >= 0 {
+		result.Workspace = strconv.Itoa(*info.Workspace + 1)
+	}
+	if info.Monitor != nil && *info.Monitor >= 0 {
+		result.Monitor = strconv.Itoa(*info.Monitor)
+	}
+	if info.Width > 0 && info.Height > 0 {
+		result.Geometry = &window.Geometry{X: info.X, Y: info.Y, Width: info.Width, Height: info.Height}
+	}
+	return result
+}
+
+// lookupWorkspaceNames returns the workspace names configured in GNOME's settings
+func (g *GNOMEProvider) lookupWorkspaceNames() []string {
+	if g.workspaceNames != nil {
+		return g.workspaceNames()
+	}
+	return gnomeWorkspaceNames()
+}
+
+// nameWorkspace replaces the workspace number with its configured name, if there is one
+func nameWorkspace(info *window.WindowInfo, names func() []string) {
+	if info.GNOME == nil || info.GNOME.WorkspaceIndex == nil {
+		return
+	}
+	if name := gnomeWorkspaceName(*info.GNOME.WorkspaceIndex, names()); name != "" {
+		info.Workspace = name
+	}
+}
+
+// gnomeWorkspaceName returns the configured name of the workspace at index, or ""
+func gnomeWorkspaceName(index int, names []string) string {
+	if index < 0 || index >= len(names) {
+		return ""
+	}
+	return names[index]
+}
 
-	var info focusedWindowInfo
-	err = json.Unmarshal([]byte(result), &info)
+// gnomeWorkspaceNames reads org.gnome.desktop.wm.preferences workspace-names,
+// returning nil when gsettings isn't available
+func gnomeWorkspaceNames() []string {
+	output, err := exec.Command("gsettings", "get", "org.gnome.desktop.wm.preferences", "workspace-names").Output()
+	if err != nil {
+		return nil
+	}
+	names, err := parseGVariantStrings(string(output))
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal focused window info: %w", err)
+		return nil
 	}
+	return names
+}
+
+// parseGVariantStrings parses a string array in GVariant text format as printed
+// by gsettings, e.g. ['Main', "Bob's"] or @as []
+func parseGVariantStrings(text string) ([]string, error) {
+	text = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(text), "@as"))
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return nil, fmt.Errorf("not a string array: %q", text)
+	}
+	text = text[1 : len(text)-1]
+
+	var names []string
+	for {
+		text = strings.TrimLeft(text, " ,")
+		if text == "" {
+			return names, nil
+		}
 
-	return &window.WindowInfo{
-		Title:     info.Title,
-		Class:     info.WmClass,
-		PID:       info.Pid,
-		Workspace: fmt.Sprintf("%d", info.Id), // Using window ID as workspace for now
-	}, nil
-}
\ No newline at end of file
+		quote := text[0]
+		if quote != '\'' && quote != '"' {
+			return nil, fmt.Errorf("expected a quoted string at %q", text)
+		}
+
+		var name strings.Builder
+		i := 1
+		for ; i < len(text) && text[i] != quote; i++ {
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+			}
+			name.WriteByte(text[i])
+		}
+		if i >= len(text) {
+			return nil, fmt.Errorf("unterminated string in %q", text)
+		}
+		names = append(names, name.String())
+		text = text[i+1:]
+	}
+}
+
+// connect opens the bus used to talk to GNOME Shell
+func (g *GNOMEProvider) connect() (gnomeBus, error) {
+	if g.bus != nil {
+		return g.bus()
+	}
+	return newGnomeSessionBus()
+}
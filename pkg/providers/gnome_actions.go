@@ -0,0 +1,124 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// gnomeWindowID parses a window ID as reported in WindowInfo.ID
+func gnomeWindowID(id string) (uint64, error) {
+	windowID, err := strconv.ParseUint(strings.TrimSpace(id), 10, 64)
+	if err != nil || windowID == 0 {
+		return 0, fmt.Errorf("invalid window ID %q: expected a GNOME window ID", id)
+	}
+	return windowID, nil
+}
+
+// gnomeFallback carries out an action without yawi's extension
+type gnomeFallback func(bus gnomeBus, windowID uint64) error
+
+// windowAction runs method on yawi's extension, falling back to fallback
+// (if the action can be done without it) when the extension isn't there
+func (g *GNOMEProvider) windowAction(id, method string, fallback gnomeFallback, args ...any) error {
+	windowID, err := gnomeWindowID(id)
+	if err != nil {
+		return err
+	}
+
+	bus, err := g.connect()
+	if err != nil {
+		return err
+	}
+	defer bus.Close()
+
+	yawiErr := bus.call(gnomeYawiPath, gnomeYawiIface+"."+method, append([]any{windowID}, args...))
+	if yawiErr == nil {
+		return nil
+	}
+	if fallback == nil {
+		return fmt.Errorf("%s needs yawi's extension ('yawi gnome install-extension'): %w", method, yawiErr)
+	}
+
+	fallbackErr := fallback(bus, windowID)
+	if fallbackErr == nil {
+		return nil
+	}
+	return fmt.Errorf("%s failed\ntried:\n  yawi extension: %v\n  Window Calls extension: %v", method, yawiErr, fallbackErr)
+}
+
+// windowCalls returns a fallback calling the Window Calls extension's method,
+// which takes 32-bit window IDs
+func windowCalls(method string, args ...any) gnomeFallback {
+	return func(bus gnomeBus, windowID uint64) error {
+		return bus.call(gnomeWindowCallsPath, gnomeWindowCallsIface+"."+method, append([]any{uint32(windowID)}, args...))
+	}
+}
+
+// windowCallsToggleMaximize checks the window's state, as Window Calls only
+// has separate Maximize and Unmaximize calls
+func windowCallsToggleMaximize(bus gnomeBus, windowID uint64) error {
+	details, err := windowCallsDetails(bus, windowID)
+	if err != nil {
+		return err
+	}
+	if details.Maximized != 0 {
+		return windowCalls("Unmaximize")(bus, windowID)
+	}
+	return windowCalls("Maximize")(bus, windowID)
+}
+
+// FocusWindow activates the window, switching to its workspace
+func (g *GNOMEProvider) FocusWindow(id string) error {
+	return g.windowAction(id, "Activate", windowCalls("Activate"))
+}
+
+// CloseWindow asks the window to close
+func (g *GNOMEProvider) CloseWindow(id string) error {
+	return g.windowAction(id, "Close", windowCalls("Close"))
+}
+
+// MinimizeWindow minimizes the window
+func (g *GNOMEProvider) MinimizeWindow(id string) error {
+	return g.windowAction(id, "Minimize", windowCalls("Minimize"))
+}
+
+// ToggleMaximize maximizes the window, or restores it if it is maximized
+func (g *GNOMEProvider) ToggleMaximize(id string) error {
+	return g.windowAction(id, "ToggleMaximize", windowCallsToggleMaximize)
+}
+
+// ToggleFullscreen switches the window in and out of fullscreen; only yawi's extension can do this
+func (g *GNOMEProvider) ToggleFullscreen(id string) error {
+	return g.windowAction(id, "ToggleFullscreen", nil)
+}
+
+// ToggleFloating isn't meaningful on GNOME, where every window floats
+func (g *GNOMEProvider) ToggleFloating(id string) error {
+	return fmt.Errorf("GNOME Shell doesn't tile windows, so there is no floating mode to toggle")
+}
+
+// MoveWindowToWorkspace moves the window to a workspace given by number
+// (counting from 1) or by its configured name
+func (g *GNOMEProvider) MoveWindowToWorkspace(id, workspace string) error {
+	index, err := g.workspaceIndex(workspace)
+	if err != nil {
+		return err
+	}
+	return g.windowAction(id, "MoveToWorkspace", windowCalls("MoveToWorkspace", uint32(index)), uint32(index))
+}
+
+// workspaceIndex resolves a workspace name or 1-based number to GNOME's zero-based index
+func (g *GNOMEProvider) workspaceIndex(workspace string) (int, error) {
+	for i, name := range g.lookupWorkspaceNames() {
+		if name == workspace {
+			return i, nil
+		}
+	}
+
+	number, err := strconv.Atoi(workspace)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("unknown workspace %q: expected a number from 1 or a configured workspace name", workspace)
+	}
+	return number - 1, nil
+}
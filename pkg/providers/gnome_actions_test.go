@@ -0,0 +1,99 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGNOMEProvider_ActionsWithYawiExtension(t *testing.T) {
+	done := gnomeReply()
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.Yawi.Activate":         done,
+		"org.gnome.Shell.Extensions.Yawi.Close":            done,
+		"org.gnome.Shell.Extensions.Yawi.Minimize":         done,
+		"org.gnome.Shell.Extensions.Yawi.ToggleMaximize":   done,
+		"org.gnome.Shell.Extensions.Yawi.ToggleFullscreen": done,
+		"org.gnome.Shell.Extensions.Yawi.MoveToWorkspace":  done,
+	}}
+	provider := gnomeProviderWith(bus)
+	provider.workspaceNames = func() []string { return []string{"Main", "Web"} }
+
+	actions := []struct {
+		run  func() error
+		want string
+	}{
+		{func() error { return provider.FocusWindow("4294967301") }, "org.gnome.Shell.Extensions.Yawi.Activate 4294967301"},
+		{func() error { return provider.CloseWindow("7") }, "org.gnome.Shell.Extensions.Yawi.Close 7"},
+		{func() error { return provider.MinimizeWindow("7") }, "org.gnome.Shell.Extensions.Yawi.Minimize 7"},
+		{func() error { return provider.ToggleMaximize("7") }, "org.gnome.Shell.Extensions.Yawi.ToggleMaximize 7"},
+		{func() error { return provider.ToggleFullscreen("7") }, "org.gnome.Shell.Extensions.Yawi.ToggleFullscreen 7"},
+		{func() error { return provider.MoveWindowToWorkspace("7", "3") }, "org.gnome.Shell.Extensions.Yawi.MoveToWorkspace 7 2"},
+		{func() error { return provider.MoveWindowToWorkspace("7", "Web") }, "org.gnome.Shell.Extensions.Yawi.MoveToWorkspace 7 1"},
+	}
+	for _, action := range actions {
+		if err := action.run(); err != nil {
+			t.Errorf("%s: %v", action.want, err)
+			continue
+		}
+		if got := bus.calls[len(bus.calls)-1]; got != action.want {
+			t.Errorf("call = %q, want %q", got, action.want)
+		}
+	}
+}
+
+func TestGNOMEProvider_ActionsWithWindowCalls(t *testing.T) {
+	done := gnomeReply()
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.Windows.Activate":        done,
+		"org.gnome.Shell.Extensions.Windows.MoveToWorkspace": done,
+		"org.gnome.Shell.Extensions.Windows.Unmaximize":      done,
+		"org.gnome.Shell.Extensions.Windows.Details":         gnomeReply(`{"id":7,"title":"~","maximized":3}`),
+	}}
+	provider := gnomeProviderWith(bus)
+
+	if err := provider.FocusWindow("7"); err != nil {
+		t.Errorf("FocusWindow() error: %v", err)
+	}
+	if got := bus.calls[len(bus.calls)-1]; got != "org.gnome.Shell.Extensions.Windows.Activate 7" {
+		t.Errorf("call = %q", got)
+	}
+
+	if err := provider.MoveWindowToWorkspace("7", "2"); err != nil {
+		t.Errorf("MoveWindowToWorkspace() error: %v", err)
+	}
+	if got := bus.calls[len(bus.calls)-1]; got != "org.gnome.Shell.Extensions.Windows.MoveToWorkspace 7 1" {
+		t.Errorf("call = %q", got)
+	}
+
+	// A maximized window is restored
+	if err := provider.ToggleMaximize("7"); err != nil {
+		t.Errorf("ToggleMaximize() error: %v", err)
+	}
+	if got := bus.calls[len(bus.calls)-1]; got != "org.gnome.Shell.Extensions.Windows.Unmaximize 7" {
+		t.Errorf("call = %q", got)
+	}
+
+	// Window Calls can't do fullscreen, and neither extension answered Close
+	if err := provider.ToggleFullscreen("7"); err == nil || !strings.Contains(err.Error(), "yawi gnome install-extension") {
+		t.Errorf("ToggleFullscreen() error = %v", err)
+	}
+	if err := provider.CloseWindow("7"); err == nil || !strings.Contains(err.Error(), "Window Calls extension:") {
+		t.Errorf("CloseWindow() error = %v", err)
+	}
+}
+
+func TestGNOMEProvider_ActionArguments(t *testing.T) {
+	provider := gnomeProviderWith(&fakeGnomeBus{})
+
+	if err := provider.FocusWindow("kitty"); err == nil || !strings.Contains(err.Error(), "invalid window ID") {
+		t.Errorf("FocusWindow(kitty) error = %v", err)
+	}
+	for _, workspace := range []string{"0", "Chat"} {
+		if err := provider.MoveWindowToWorkspace("7", workspace); err == nil || !strings.Contains(err.Error(), "unknown workspace") {
+			t.Errorf("MoveWindowToWorkspace(%q) error = %v", workspace, err)
+		}
+	}
+	if err := provider.ToggleFloating("7"); err == nil {
+		t.Error("ToggleFloating() should fail on GNOME")
+	}
+}
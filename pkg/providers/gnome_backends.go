@@ -0,0 +1,206 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// gnomeBackend is one way of asking GNOME Shell for the focused window. On
+// Wayland GNOME doesn't expose this by default, so we try what might be there.
+type gnomeBackend struct {
+	name         string
+	activeWindow func(bus gnomeBus) (*window.WindowInfo, error)
+}
+
+// gnomeBackends are tried in order; extensions first since they work on
+// stock GNOME, the Shell's own interfaces need extra permissions. yawi's own
+// extension reports the most, so it goes first.
+var gnomeBackends = []gnomeBackend{
+	{name: "yawi extension", activeWindow: yawiExtensionActive},
+	{name: "FocusedWindow extension", activeWindow: focusedWindowExtensionActive},
+	{name: "Window Calls extension", activeWindow: windowCallsActive},
+	{name: "Shell Introspect", activeWindow: introspectActive},
+	{name: "Shell Eval", activeWindow: evalActive},
+}
+
+// yawiExtensionActive asks yawi's own extension (see 'yawi gnome install-extension')
+func yawiExtensionActive(bus gnomeBus) (*window.WindowInfo, error) {
+	var result string
+	if err := bus.call(gnomeYawiPath, gnomeYawiIface+".GetFocusedWindow", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to call Yawi.GetFocusedWindow D-Bus method: %w", err)
+	}
+	if result == "null" {
+		return nil, fmt.Errorf("no focused window")
+	}
+
+	var info focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal focused window info: %w", err)
+	}
+	return info.toWindowInfo(), nil
+}
+
+// yawiExtensionList returns every normal window known to yawi's extension
+func yawiExtensionList(bus gnomeBus) ([]focusedWindowInfo, error) {
+	var result string
+	if err := bus.call(gnomeYawiPath, gnomeYawiIface+".ListWindows", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to call Yawi.ListWindows D-Bus method: %w", err)
+	}
+
+	var windows []focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &windows); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal window list: %w", err)
+	}
+	return windows, nil
+}
+
+// focusedWindowExtensionActive asks the Focused Window D-Bus extension
+func focusedWindowExtensionActive(bus gnomeBus) (*window.WindowInfo, error) {
+	var result string
+	if err := bus.call(gnomeFocusedWindowPath, gnomeFocusedWindowIface+".Get", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to call FocusedWindow.Get D-Bus method: %w", err)
+	}
+
+	var info focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal focused window info: %w", err)
+	}
+	return info.toWindowInfo(), nil
+}
+
+// windowCallsActive finds the focused window in the Window Calls extension's
+// list and fetches its details
+func windowCallsActive(bus gnomeBus) (*window.WindowInfo, error) {
+	windows, err := windowCallsList(bus)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range windows {
+		if !w.Focus {
+			continue
+		}
+		details, err := windowCallsDetails(bus, w.Id)
+		if err != nil {
+			return nil, err
+		}
+		return details.toWindowInfo(), nil
+	}
+	return nil, fmt.Errorf("no focused window")
+}
+
+// windowCallsList returns the windows known to the Window Calls extension
+func windowCallsList(bus gnomeBus) ([]focusedWindowInfo, error) {
+	var result string
+	if err := bus.call(gnomeWindowCallsPath, gnomeWindowCallsIface+".List", nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to call Windows.List D-Bus method: %w", err)
+	}
+
+	var windows []focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &windows); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal window list: %w", err)
+	}
+	return windows, nil
+}
+
+// windowCallsDetails returns everything Window Calls knows about one window.
+// Newer releases moved the title out of Details into GetTitle.
+func windowCallsDetails(bus gnomeBus, id uint64) (*focusedWindowInfo, error) {
+	var result string
+	if err := bus.call(gnomeWindowCallsPath, gnomeWindowCallsIface+".Details", []any{uint32(id)}, &result); err != nil {
+		return nil, fmt.Errorf("failed to call Windows.Details D-Bus method: %w", err)
+	}
+
+	var info focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal window details: %w", err)
+	}
+
+	if info.Title == "" {
+		var title string
+		if err := bus.call(gnomeWindowCallsPath, gnomeWindowCallsIface+".GetTitle", []any{uint32(id)}, &title); err == nil {
+			info.Title = title
+		}
+	}
+	return &info, nil
+}
+
+// introspectActive uses org.gnome.Shell.Introspect, which GNOME only answers
+// for allowed callers (or with unsafe mode enabled)
+func introspectActive(bus gnomeBus) (*window.WindowInfo, error) {
+	var windows map[uint64]map[string]dbus.Variant
+	if err := bus.call(gnomeIntrospectPath, gnomeIntrospectIface+".GetWindows", nil, &windows); err != nil {
+		return nil, fmt.Errorf("failed to call Introspect.GetWindows D-Bus method: %w", err)
+	}
+
+	for id, props := range windows {
+		if focus, _ := props["has-focus"].Value().(bool); !focus {
+			continue
+		}
+		return introspectWindowInfo(id, props), nil
+	}
+	return nil, fmt.Errorf("no focused window")
+}
+
+// introspectWindowInfo converts the properties of one Introspect window
+func introspectWindowInfo(id uint64, props map[string]dbus.Variant) *window.WindowInfo {
+	info := &window.WindowInfo{
+		ID:    fmt.Sprintf("%d", id),
+		GNOME: &window.GNOMEDetails{WindowID: id},
+	}
+	info.Title, _ = props["title"].Value().(string)
+	info.Class, _ = props["wm-class"].Value().(string)
+	info.AppID, _ = props["app-id"].Value().(string)
+
+	// client-type is 0 for Wayland and 1 for X11 clients
+	if clientType, ok := props["client-type"].Value().(uint32); ok {
+		info.XWayland = window.Bool(clientType == 1)
+	}
+	return info
+}
+
+// gnomeEvalFocusedWindow is run by Shell.Eval; GNOME Shell JSON-encodes the result
+const gnomeEvalFocusedWindow = `(() => {
+	const w = global.display.focus_window;
+	if (!w) return null;
+	return {
+		title: w.get_title(),
+		wm_class: w.get_wm_class(),
+		wm_class_instance: w.get_wm_class_instance(),
+		pid: w.get_pid(),
+		id: w.get_id(),
+		workspace: w.get_workspace()?.index() ?? -1,
+		monitor: w.get_monitor(),
+		x: w.get_frame_rect().x,
+		y: w.get_frame_rect().y,
+		width: w.get_frame_rect().width,
+		height: w.get_frame_rect().height,
+	};
+})()`
+
+// evalActive runs a snippet in GNOME Shell, which only works in unsafe mode
+func evalActive(bus gnomeBus) (*window.WindowInfo, error) {
+	var success bool
+	var result string
+	if err := bus.call(gnomeShellPath, gnomeShellIface+".Eval", []any{gnomeEvalFocusedWindow}, &success, &result); err != nil {
+		return nil, fmt.Errorf("failed to call Shell.Eval D-Bus method: %w", err)
+	}
+	if !success {
+		if result == "" {
+			return nil, fmt.Errorf("unsafe mode is off, so Eval is disabled")
+		}
+		return nil, fmt.Errorf("script failed: %s", result)
+	}
+	if result == "" || result == "null" {
+		return nil, fmt.Errorf("no focused window")
+	}
+
+	var info focusedWindowInfo
+	if err := json.Unmarshal([]byte(result), &info); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal Eval result: %w", err)
+	}
+	return info.toWindowInfo(), nil
+}
@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// GNOME Shell's bus name and the object paths yawi talks to
+const (
+	gnomeShellName          = "org.gnome.Shell"
+	gnomeShellPath          = dbus.ObjectPath("/org/gnome/Shell")
+	gnomeIntrospectPath     = dbus.ObjectPath("/org/gnome/Shell/Introspect")
+	gnomeFocusedWindowPath  = dbus.ObjectPath("/org/gnome/shell/extensions/FocusedWindow")
+	gnomeWindowCallsPath    = dbus.ObjectPath("/org/gnome/Shell/Extensions/Windows")
+	gnomeFocusedWindowIface = "org.gnome.shell.extensions.FocusedWindow"
+	gnomeWindowCallsIface   = "org.gnome.Shell.Extensions.Windows"
+	gnomeIntrospectIface    = "org.gnome.Shell.Introspect"
+	gnomeShellIface         = "org.gnome.Shell"
+
+	// yawi's own extension, which also signals focus changes
+	gnomeYawiPath  = dbus.ObjectPath("/org/gnome/Shell/Extensions/Yawi")
+	gnomeYawiIface = "org.gnome.Shell.Extensions.Yawi"
+)
+
+// gnomeBus is the part of the session bus the GNOME backends need, so tests
+// can stand in for GNOME Shell
+type gnomeBus interface {
+	// call invokes method on a GNOME Shell object and stores the reply values in results
+	call(path dbus.ObjectPath, method string, args []any, results ...any) error
+	// property reads a property of a GNOME Shell object
+	property(path dbus.ObjectPath, name string) (dbus.Variant, error)
+	// signals delivers a GNOME Shell object's signal until the bus is closed
+	signals(path dbus.ObjectPath, iface, member string) (<-chan *dbus.Signal, error)
+	Close() error
+}
+
+// gnomeSessionBus talks to GNOME Shell over a private session bus connection
+type gnomeSessionBus struct {
+	conn *dbus.Conn
+}
+
+// newGnomeSessionBus connects to the D-Bus session bus
+func newGnomeSessionBus() (*gnomeSessionBus, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to D-Bus session bus: %w", err)
+	}
+	return &gnomeSessionBus{conn: conn}, nil
+}
+
+func (b *gnomeSessionBus) call(path dbus.ObjectPath, method string, args []any, results ...any) error {
+	return b.conn.Object(gnomeShellName, path).Call(method, 0, args...).Store(results...)
+}
+
+func (b *gnomeSessionBus) property(path dbus.ObjectPath, name string) (dbus.Variant, error) {
+	return b.conn.Object(gnomeShellName, path).GetProperty(name)
+}
+
+func (b *gnomeSessionBus) signals(path dbus.ObjectPath, iface, member string) (<-chan *dbus.Signal, error) {
+	err := b.conn.AddMatchSignal(
+		dbus.WithMatchSender(gnomeShellName),
+		dbus.WithMatchObjectPath(path),
+		dbus.WithMatchInterface(iface),
+		dbus.WithMatchMember(member),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to %s.%s: %w", iface, member, err)
+	}
+
+	// godbus closes the channel when the connection closes
+	ch := make(chan *dbus.Signal, 16)
+	b.conn.Signal(ch)
+	return ch, nil
+}
+
+// Close closes the connection
+func (b *gnomeSessionBus) Close() error {
+	return b.conn.Close()
+}
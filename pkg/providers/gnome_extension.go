@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// GNOMEExtensionUUID identifies yawi's GNOME Shell extension
+const GNOMEExtensionUUID = "yawi@alde.github.io"
+
+//go:embed gnome-extension
+var gnomeExtensionFiles embed.FS
+
+// gnomeExtensionDir is the embedded directory holding the extension
+const gnomeExtensionDir = "gnome-extension"
+
+// GNOMEExtensionPath returns where the extension is installed for the current
+// user, honouring $XDG_DATA_HOME
+func GNOMEExtensionPath() (string, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "gnome-shell", "extensions", GNOMEExtensionUUID), nil
+}
+
+// InstallGNOMEExtension writes the bundled extension into the user's extension
+// directory, replacing any older copy, and returns where it went
+func InstallGNOMEExtension() (string, error) {
+	target, err := GNOMEExtensionPath()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(target, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create extension directory: %w", err)
+	}
+
+	files, err := fs.ReadDir(gnomeExtensionFiles, gnomeExtensionDir)
+	if err != nil {
+		return "", err
+	}
+	for _, file := range files {
+		data, err := gnomeExtensionFiles.ReadFile(gnomeExtensionDir + "/" + file.Name())
+		if err != nil {
+			return "", err
+		}
+		if err := os.WriteFile(filepath.Join(target, file.Name()), data, 0o644); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", file.Name(), err)
+		}
+	}
+	return target, nil
+}
+
+// GNOMEExtensionStatus describes how far along yawi's extension is
+type GNOMEExtensionStatus struct {
+	Path string `json:"path"`
+	// Installed is true when the extension files are in Path
+	Installed bool `json:"installed"`
+	// InstalledVersion and BundledVersion differ when the install is out of date
+	InstalledVersion int `json:"installed_version,omitempty"`
+	BundledVersion   int `json:"bundled_version"`
+	// Enabled reflects the user's GNOME settings, State what GNOME Shell reports
+	Enabled bool   `json:"enabled"`
+	State   string `json:"state,omitempty"`
+	Error   string `json:"error,omitempty"`
+	// Running is true when the extension answers on D-Bus
+	Running bool `json:"running"`
+}
+
+// gnomeExtensionMetadata is the part of metadata.json yawi reads
+type gnomeExtensionMetadata struct {
+	UUID    string `json:"uuid"`
+	Version int    `json:"version"`
+}
+
+// gnomeExtensionStates names GNOME Shell's ExtensionState values
+var gnomeExtensionStates = map[int]string{
+	1:  "enabled",
+	2:  "disabled",
+	3:  "error",
+	4:  "out of date",
+	5:  "downloading",
+	6:  "initialized",
+	7:  "disabling",
+	8:  "enabling",
+	99: "uninstalled",
+}
+
+// ExtensionStatus reports whether yawi's extension is installed, enabled and running.
+// Only the installed part is known when GNOME Shell can't be reached.
+func (g *GNOMEProvider) ExtensionStatus() (*GNOMEExtensionStatus, error) {
+	path, err := GNOMEExtensionPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := gnomeExtensionFiles.ReadFile(gnomeExtensionDir + "/metadata.json")
+	if err != nil {
+		return nil, err
+	}
+	bundled, err := parseGnomeExtensionMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &GNOMEExtensionStatus{Path: path, BundledVersion: bundled.Version}
+	if data, err := os.ReadFile(filepath.Join(path, "metadata.json")); err == nil {
+		if installed, err := parseGnomeExtensionMetadata(data); err == nil {
+			status.Installed = true
+			status.InstalledVersion = installed.Version
+		}
+	}
+
+	bus, err := g.connect()
+	if err != nil {
+		return status, err
+	}
+	defer bus.Close()
+
+	var info map[string]dbus.Variant
+	if err := bus.call(gnomeShellPath, "org.gnome.Shell.Extensions.GetExtensionInfo", []any{GNOMEExtensionUUID}, &info); err != nil {
+		return status, fmt.Errorf("failed to ask GNOME Shell about the extension: %w", err)
+	}
+	if state, ok := info["state"].Value().(float64); ok {
+		status.State = gnomeExtensionStates[int(state)]
+		status.Enabled = int(state) == 1
+	}
+	if enabled, ok := info["enabled"].Value().(bool); ok {
+		status.Enabled = enabled
+	}
+	status.Error, _ = info["error"].Value().(string)
+
+	var focused string
+	status.Running = bus.call(gnomeYawiPath, gnomeYawiIface+".GetFocusedWindow", nil, &focused) == nil
+	return status, nil
+}
+
+// parseGnomeExtensionMetadata decodes an extension's metadata.json
+func parseGnomeExtensionMetadata(data []byte) (*gnomeExtensionMetadata, error) {
+	var metadata gnomeExtensionMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return nil, fmt.Errorf("failed to parse extension metadata: %w", err)
+	}
+	return &metadata, nil
+}
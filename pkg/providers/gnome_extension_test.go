@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestInstallGNOMEExtension(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	path, err := InstallGNOMEExtension()
+	if err != nil {
+		t.Fatalf("InstallGNOMEExtension() error: %v", err)
+	}
+	if want := filepath.Join(dataHome, "gnome-shell", "extensions", GNOMEExtensionUUID); path != want {
+		t.Errorf("path = %q, want %q", path, want)
+	}
+
+	// GNOME Shell refuses extensions whose directory doesn't match the metadata UUID
+	data, err := os.ReadFile(filepath.Join(path, "metadata.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata, err := parseGnomeExtensionMetadata(data)
+	if err != nil || metadata.UUID != GNOMEExtensionUUID {
+		t.Errorf("installed metadata = %+v, %v", metadata, err)
+	}
+	if _, err := os.Stat(filepath.Join(path, "extension.js")); err != nil {
+		t.Errorf("extension.js missing: %v", err)
+	}
+}
+
+func TestGNOMEProvider_ExtensionStatus(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.GetExtensionInfo": gnomeReply(map[string]dbus.Variant{
+			"state":   dbus.MakeVariant(float64(1)),
+			"enabled": dbus.MakeVariant(true),
+		}),
+		"org.gnome.Shell.Extensions.Yawi.GetFocusedWindow": gnomeReply("null"),
+	}}
+
+	status, err := gnomeProviderWith(bus).ExtensionStatus()
+	if err != nil {
+		t.Fatalf("ExtensionStatus() error: %v", err)
+	}
+	if status.Installed || !status.Enabled || status.State != "enabled" || !status.Running || status.BundledVersion == 0 {
+		t.Errorf("status before install = %+v", status)
+	}
+
+	if _, err := InstallGNOMEExtension(); err != nil {
+		t.Fatal(err)
+	}
+	status, err = gnomeProviderWith(bus).ExtensionStatus()
+	if err != nil || !status.Installed || status.InstalledVersion != status.BundledVersion {
+		t.Errorf("status after install = %+v, %v", status, err)
+	}
+}
+
+func TestGNOMEProvider_ExtensionStatusWithoutShell(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	status, err := gnomeProviderWith(&fakeGnomeBus{}).ExtensionStatus()
+	if err == nil {
+		t.Error("expected an error when GNOME Shell doesn't answer")
+	}
+	if status == nil || status.Installed || status.Running {
+		t.Errorf("status = %+v, want the local part filled in", status)
+	}
+}
@@ -0,0 +1,303 @@
+package providers
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeGnomeBus answers method calls with canned replies; methods without a
+// reply fail like a missing extension would
+type fakeGnomeBus struct {
+	replies    map[string]func(args []any) []any
+	properties map[string]dbus.Variant
+	// focusSignals is handed out for FocusChanged subscriptions; nil fails them
+	focusSignals chan *dbus.Signal
+	calls        []string
+}
+
+func (b *fakeGnomeBus) call(path dbus.ObjectPath, method string, args []any, results ...any) error {
+	b.calls = append(b.calls, strings.TrimSpace(fmt.Sprintln(append([]any{method}, args...)...)))
+	reply, ok := b.replies[method]
+	if !ok {
+		return fmt.Errorf("no such interface on %s", path)
+	}
+	return dbus.Store(reply(args), results...)
+}
+
+func (b *fakeGnomeBus) property(path dbus.ObjectPath, name string) (dbus.Variant, error) {
+	value, ok := b.properties[name]
+	if !ok {
+		return dbus.Variant{}, fmt.Errorf("no such property %s", name)
+	}
+	return value, nil
+}
+
+func (b *fakeGnomeBus) signals(path dbus.ObjectPath, iface, member string) (<-chan *dbus.Signal, error) {
+	b.calls = append(b.calls, "subscribe "+member)
+	if b.focusSignals == nil || member != "FocusChanged" {
+		return nil, fmt.Errorf("no signals")
+	}
+	return b.focusSignals, nil
+}
+
+func (b *fakeGnomeBus) Close() error { return nil }
+
+// gnomeProviderWith returns a provider talking to bus
+func gnomeProviderWith(bus *fakeGnomeBus) *GNOMEProvider {
+	return &GNOMEProvider{
+		bus:            func() (gnomeBus, error) { return bus, nil },
+		workspaceNames: func() []string { return nil },
+	}
+}
+
+// gnomeReply returns a handler that always answers with values
+func gnomeReply(values ...any) func([]any) []any {
+	return func([]any) []any { return values }
+}
+
+func TestGNOMEProvider_BackendOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		replies   map[string]func([]any) []any
+		wantClass string
+	}{
+		{
+			name: "FocusedWindow extension",
+			replies: map[string]func([]any) []any{
+				"org.gnome.shell.extensions.FocusedWindow.Get": gnomeReply(`{"title":"Inbox","wm_class":"Thunderbird","pid":12,"id":3}`),
+				"org.gnome.Shell.Extensions.Windows.List":      gnomeReply(`[]`),
+			},
+			wantClass: "Thunderbird",
+		},
+		{
+			name: "Window Calls extension",
+			replies: map[string]func([]any) []any{
+				"org.gnome.Shell.Extensions.Windows.List":     gnomeReply(`[{"wm_class":"kitty","id":7,"focus":false},{"wm_class":"firefox","id":9,"focus":true}]`),
+				"org.gnome.Shell.Extensions.Windows.Details":  gnomeReply(`{"wm_class":"firefox","pid":99,"id":9}`),
+				"org.gnome.Shell.Extensions.Windows.GetTitle": gnomeReply("Mozilla Firefox"),
+			},
+			wantClass: "firefox",
+		},
+		{
+			name: "Shell Introspect",
+			replies: map[string]func([]any) []any{
+				"org.gnome.Shell.Introspect.GetWindows": gnomeReply(map[uint64]map[string]dbus.Variant{
+					1: {"title": dbus.MakeVariant("Terminal"), "wm-class": dbus.MakeVariant("Console"), "has-focus": dbus.MakeVariant(false)},
+					2: {"title": dbus.MakeVariant("Files"), "wm-class": dbus.MakeVariant("org.gnome.Nautilus"), "has-focus": dbus.MakeVariant(true), "client-type": dbus.MakeVariant(uint32(0))},
+				}),
+			},
+			wantClass: "org.gnome.Nautilus",
+		},
+		{
+			name: "Shell Eval",
+			replies: map[string]func([]any) []any{
+				"org.gnome.Shell.Eval": gnomeReply(true, `{"title":"notes.txt","wm_class":"gedit","pid":5,"id":11}`),
+			},
+			wantClass: "gedit",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := &fakeGnomeBus{replies: tt.replies}
+			info, err := gnomeProviderWith(bus).GetActiveWindow()
+			if err != nil {
+				t.Fatalf("GetActiveWindow() error: %v", err)
+			}
+			if info.Class != tt.wantClass {
+				t.Errorf("Class = %q, want %q", info.Class, tt.wantClass)
+			}
+		})
+	}
+}
+
+func TestGNOMEProvider_WindowCallsTitle(t *testing.T) {
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.Windows.List":     gnomeReply(`[{"wm_class":"firefox","id":9,"focus":true}]`),
+		"org.gnome.Shell.Extensions.Windows.Details":  gnomeReply(`{"wm_class":"firefox","pid":99,"id":9}`),
+		"org.gnome.Shell.Extensions.Windows.GetTitle": gnomeReply("Mozilla Firefox"),
+	}}
+
+	info, err := gnomeProviderWith(bus).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Title != "Mozilla Firefox" || info.PID != 99 {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+	if got := bus.calls[len(bus.calls)-1]; got != "org.gnome.Shell.Extensions.Windows.GetTitle 9" {
+		t.Errorf("last call = %q", got)
+	}
+}
+
+func TestGNOMEProvider_AllBackendsFail(t *testing.T) {
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Introspect.GetWindows": func([]any) []any {
+			return []any{map[uint64]map[string]dbus.Variant{}}
+		},
+		"org.gnome.Shell.Eval": gnomeReply(false, ""),
+	}}
+
+	_, err := gnomeProviderWith(bus).GetActiveWindow()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{
+		"FocusedWindow extension: failed to call FocusedWindow.Get",
+		"Window Calls extension: failed to call Windows.List",
+		"Shell Introspect: no focused window",
+		"Shell Eval: unsafe mode is off",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestGNOMEProvider_CompositorVersion(t *testing.T) {
+	bus := &fakeGnomeBus{properties: map[string]dbus.Variant{
+		"org.gnome.Shell.ShellVersion": dbus.MakeVariant("46.2"),
+	}}
+
+	version, err := gnomeProviderWith(bus).CompositorVersion()
+	if err != nil || version != "46.2" {
+		t.Errorf("CompositorVersion() = %q, %v", version, err)
+	}
+}
+
+func TestGNOMEProvider_Workspace(t *testing.T) {
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Eval": gnomeReply(true, `{"title":"notes.txt","wm_class":"gedit","wm_class_instance":"gedit","pid":5,"id":2863311530,
+			"workspace":1,"monitor":0,"x":10,"y":20,"width":800,"height":600}`),
+	}}
+	provider := gnomeProviderWith(bus)
+
+	info, err := provider.GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	workspace, monitor := 1, 0
+	expected := &window.WindowInfo{
+		ID:        "2863311530",
+		Title:     "notes.txt",
+		Class:     "gedit",
+		Instance:  "gedit",
+		PID:       5,
+		Workspace: "2",
+		Monitor:   "0",
+		Geometry:  &window.Geometry{X: 10, Y: 20, Width: 800, Height: 600},
+		GNOME:     &window.GNOMEDetails{WindowID: 2863311530, WorkspaceIndex: &workspace, MonitorIndex: &monitor},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetActiveWindow() = %+v\nwant %+v", info, expected)
+	}
+
+	provider.workspaceNames = func() []string { return []string{"Main", "Web"} }
+	info, err = provider.GetActiveWindow()
+	if err != nil || info.Workspace != "Web" {
+		t.Errorf("Workspace with configured names = %q, %v", info.Workspace, err)
+	}
+}
+
+func TestGNOMEProvider_UnknownWorkspace(t *testing.T) {
+	// The Focused Window extension doesn't report the workspace; leave it empty
+	// rather than guessing
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.shell.extensions.FocusedWindow.Get": gnomeReply(`{"title":"Inbox","wm_class":"Thunderbird","pid":12,"id":3,"monitor":1}`),
+	}}
+
+	info, err := gnomeProviderWith(bus).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Workspace != "" || info.Monitor != "1" || info.ID != "3" || info.GNOME.WindowID != 3 {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestParseGVariantStrings(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"@as []\n", nil},
+		{"['Main', 'Web']\n", []string{"Main", "Web"}},
+		{`["Bob's", 'say \'hi\'', 'back\\slash']`, []string{"Bob's", "say 'hi'", `back\slash`}},
+	}
+	for _, tt := range tests {
+		got, err := parseGVariantStrings(tt.input)
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseGVariantStrings(%q) = %q, %v; want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	for _, input := range []string{"", "'Main'", "['Main"} {
+		if _, err := parseGVariantStrings(input); err == nil {
+			t.Errorf("parseGVariantStrings(%q): expected an error", input)
+		}
+	}
+}
+
+func TestGNOMEProvider_ListWindows(t *testing.T) {
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.Windows.List": gnomeReply(`[{"wm_class":"kitty","id":7,"focus":false},{"wm_class":"firefox","id":9,"focus":true}]`),
+		"org.gnome.Shell.Extensions.Windows.Details": func(args []any) []any {
+			if args[0] == uint32(9) {
+				return []any{`{"wm_class":"firefox","title":"Mozilla Firefox","pid":99,"id":9,"monitor":1}`}
+			}
+			return []any{`{"wm_class":"kitty","title":"~","pid":77,"id":7,"monitor":0}`}
+		},
+	}}
+
+	windows, err := gnomeProviderWith(bus).ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	if len(windows) != 2 || windows[0].Title != "~" || windows[1].Monitor != "1" || windows[1].PID != 99 {
+		t.Errorf("unexpected windows: %+v, %+v", windows[0], windows[1])
+	}
+}
+
+func TestGNOMEProvider_ListWindowsNeedsWindowCalls(t *testing.T) {
+	_, err := gnomeProviderWith(&fakeGnomeBus{}).ListWindows()
+	if err == nil || !strings.Contains(err.Error(), "Window Calls") {
+		t.Errorf("ListWindows() error = %v", err)
+	}
+}
+
+func TestGNOMEProvider_YawiExtension(t *testing.T) {
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.Shell.Extensions.Yawi.GetFocusedWindow": gnomeReply(`{"id":5,"title":"~","wm_class":"kitty","pid":42,"workspace":2,"monitor":0}`),
+		"org.gnome.Shell.Extensions.Yawi.ListWindows":      gnomeReply(`[{"id":5,"title":"~","wm_class":"kitty","workspace":2},{"id":6,"title":"Files","wm_class":"org.gnome.Nautilus","workspace":null}]`),
+		"org.gnome.Shell.Extensions.Yawi.GetWorkspaces":    gnomeReply(`[{"index":0,"active":false,"windows":0},{"index":1,"active":true,"windows":3}]`),
+		// Window Calls is installed too, but yawi's extension wins
+		"org.gnome.Shell.Extensions.Windows.List": gnomeReply(`[]`),
+	}}
+	provider := gnomeProviderWith(bus)
+	provider.workspaceNames = func() []string { return []string{"Main"} }
+
+	info, err := provider.GetActiveWindow()
+	if err != nil || info.Class != "kitty" || info.Workspace != "3" {
+		t.Errorf("GetActiveWindow() = %+v, %v", info, err)
+	}
+
+	windows, err := provider.ListWindows()
+	if err != nil || len(windows) != 2 || windows[1].Workspace != "" {
+		t.Errorf("ListWindows() = %v, %v", windows, err)
+	}
+
+	workspaces, err := provider.ListWorkspaces()
+	if err != nil || len(workspaces) != 2 || workspaces[0].Name != "Main" || workspaces[1].Name != "2" {
+		t.Errorf("ListWorkspaces() = %v, %v", workspaces, err)
+	}
+
+	active, err := provider.ActiveWorkspace()
+	if err != nil || active.ID != 2 || !active.Visible || active.Windows != 3 {
+		t.Errorf("ActiveWorkspace() = %+v, %v", active, err)
+	}
+}
@@ -0,0 +1,141 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// gnomePollInterval is how often the focused window is polled when no
+// extension sends focus signals
+const gnomePollInterval = 250 * time.Millisecond
+
+// Watch reports active window changes. When the extension yawi bundles (see
+// 'yawi gnome install-extension') is running, its FocusChanged signal drives
+// the watch; otherwise the backend chain is polled and only changes are reported.
+func (g *GNOMEProvider) Watch(ctx context.Context) (<-chan window.Event, error) {
+	bus, err := g.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan window.Event)
+	if signals, initial, err := gnomeFocusSignals(bus); err == nil {
+		go func() {
+			defer close(out)
+			defer bus.Close()
+			g.watchSignals(ctx, out, signals, initial)
+		}()
+		return out, nil
+	}
+
+	// Fail early if no backend works at all rather than reporting "no window" forever
+	initial, backend, err := g.activeWindow(bus)
+	if err != nil {
+		bus.Close()
+		return nil, err
+	}
+
+	go func() {
+		defer close(out)
+		defer bus.Close()
+		g.poll(ctx, out, bus, backend, initial)
+	}()
+	return out, nil
+}
+
+// gnomeFocusSignals subscribes to the extension's FocusChanged signal, then
+// returns the currently focused window as JSON. Subscribing first means a focus
+// change between the two isn't lost. It fails when the extension isn't running.
+func gnomeFocusSignals(bus gnomeBus) (<-chan *dbus.Signal, string, error) {
+	signals, err := bus.signals(gnomeYawiPath, gnomeYawiIface, "FocusChanged")
+	if err != nil {
+		return nil, "", err
+	}
+
+	var initial string
+	if err := bus.call(gnomeYawiPath, gnomeYawiIface+".GetFocusedWindow", nil, &initial); err != nil {
+		return nil, "", err
+	}
+	return signals, initial, nil
+}
+
+// watchSignals emits a window for every FocusChanged signal, whose only
+// argument is the focused window as JSON ("" when nothing has focus)
+func (g *GNOMEProvider) watchSignals(ctx context.Context, out chan<- window.Event, signals <-chan *dbus.Signal, initial string) {
+	emitter := newWatchEmitter(ctx, out, "")
+	if !emitter.setWindow(g.decodeFocusedWindow(initial)) {
+		return
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case signal, ok := <-signals:
+			if !ok {
+				return
+			}
+			// The connection also gets signals from the bus itself, such as NameAcquired
+			if signal.Name != gnomeYawiIface+".FocusChanged" || signal.Path != gnomeYawiPath {
+				continue
+			}
+			var payload string
+			if len(signal.Body) > 0 {
+				payload, _ = signal.Body[0].(string)
+			}
+			if !emitter.setWindow(g.decodeFocusedWindow(payload)) {
+				return
+			}
+		}
+	}
+}
+
+// decodeFocusedWindow converts a focused window JSON payload, returning nil
+// when nothing has focus
+func (g *GNOMEProvider) decodeFocusedWindow(payload string) *window.WindowInfo {
+	if payload == "" || payload == "null" {
+		return nil
+	}
+
+	var info focusedWindowInfo
+	if err := json.Unmarshal([]byte(payload), &info); err != nil {
+		return nil
+	}
+	windowInfo := info.toWindowInfo()
+	nameWorkspace(windowInfo, g.lookupWorkspaceNames)
+	return windowInfo
+}
+
+// poll asks the backend that answered first for the focused window on every
+// tick, over the same connection, reporting only changes
+func (g *GNOMEProvider) poll(ctx context.Context, out chan<- window.Event, bus gnomeBus, backend *gnomeBackend, initial *window.WindowInfo) {
+	emitter := newWatchEmitter(ctx, out, "")
+	if !emitter.setWindow(initial) {
+		return
+	}
+
+	ticker := time.NewTicker(gnomePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			info, err := backend.activeWindow(bus)
+			if err != nil {
+				// Nothing focused, or GNOME Shell is restarting
+				info = nil
+			} else {
+				nameWorkspace(info, g.lookupWorkspaceNames)
+			}
+			if !emitter.setWindow(info) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func TestGNOMEProvider_WatchSignals(t *testing.T) {
+	bus := &fakeGnomeBus{
+		replies: map[string]func([]any) []any{
+			"org.gnome.Shell.Extensions.Yawi.GetFocusedWindow": gnomeReply(`{"title":"~","wm_class":"kitty","id":1,"workspace":0}`),
+		},
+		focusSignals: make(chan *dbus.Signal, 8),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := gnomeProviderWith(bus).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+
+	if event := <-events; event.Window == nil || event.Window.Class != "kitty" || event.Window.Workspace != "1" {
+		t.Fatalf("first event = %+v", event.Window)
+	}
+
+	focusChanged := func(payload string) *dbus.Signal {
+		return &dbus.Signal{Path: gnomeYawiPath, Name: "org.gnome.Shell.Extensions.Yawi.FocusChanged", Body: []any{payload}}
+	}
+	// Signals from the bus itself reach the same channel and must not read as "no window"
+	bus.focusSignals <- &dbus.Signal{Path: "/org/freedesktop/DBus", Name: "org.freedesktop.DBus.NameAcquired", Body: []any{":1.42"}}
+	bus.focusSignals <- focusChanged(`{"title":"~","wm_class":"kitty","id":1,"workspace":0}`)
+	bus.focusSignals <- focusChanged(`{"title":"Files","wm_class":"org.gnome.Nautilus","id":2,"workspace":0}`)
+	bus.focusSignals <- focusChanged("")
+
+	if event := <-events; event.Window == nil || event.Window.ID != "2" {
+		t.Errorf("second event = %+v, want the repeated kitty focus to be skipped", event.Window)
+	}
+	if event := <-events; event.Window != nil {
+		t.Errorf("third event = %+v, want no window", event.Window)
+	}
+
+	// The watcher stops when the connection goes away
+	close(bus.focusSignals)
+	if _, ok := <-events; ok {
+		t.Error("expected the event channel to close")
+	}
+}
+
+func TestGNOMEProvider_WatchSubscribesBeforeReading(t *testing.T) {
+	bus := &fakeGnomeBus{
+		replies: map[string]func([]any) []any{
+			"org.gnome.Shell.Extensions.Yawi.GetFocusedWindow": gnomeReply(`{"title":"~","wm_class":"kitty","id":1,"workspace":0}`),
+		},
+		focusSignals: make(chan *dbus.Signal, 8),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := gnomeProviderWith(bus).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	<-events
+
+	// A focus change between reading and subscribing would otherwise be lost
+	want := []string{"subscribe FocusChanged", "org.gnome.Shell.Extensions.Yawi.GetFocusedWindow"}
+	if !reflect.DeepEqual(bus.calls, want) {
+		t.Errorf("calls = %q, want %q", bus.calls, want)
+	}
+}
+
+func TestGNOMEProvider_WatchPolling(t *testing.T) {
+	var polls atomic.Int32
+	bus := &fakeGnomeBus{replies: map[string]func([]any) []any{
+		"org.gnome.shell.extensions.FocusedWindow.Get": func([]any) []any {
+			// Focus moves to the second window after a few polls
+			if polls.Add(1) < 3 {
+				return []any{`{"title":"Inbox","wm_class":"Thunderbird","id":3}`}
+			}
+			return []any{`{"title":"Mozilla Firefox","wm_class":"firefox","id":4}`}
+		},
+	}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := gnomeProviderWith(bus).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+
+	if event := <-events; event.Window == nil || event.Window.Class != "Thunderbird" {
+		t.Fatalf("first event = %+v", event.Window)
+	}
+	if event := <-events; event.Window == nil || event.Window.Class != "firefox" {
+		t.Errorf("second event = %+v", event.Window)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestGNOMEProvider_WatchWithoutBackends(t *testing.T) {
+	if _, err := gnomeProviderWith(&fakeGnomeBus{}).Watch(context.Background()); err == nil {
+		t.Error("expected an error when no backend answers")
+	}
+}
@@ -0,0 +1,72 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// gnomeWorkspace represents one entry of yawi's extension GetWorkspaces reply
+type gnomeWorkspace struct {
+	Index   int  `json:"index"`
+	Active  bool `json:"active"`
+	Windows int  `json:"windows"`
+}
+
+// ListWorkspaces returns GNOME's workspaces. Only yawi's extension reports them.
+func (g *GNOMEProvider) ListWorkspaces() ([]*window.WorkspaceInfo, error) {
+	bus, err := g.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	var result string
+	if err := bus.call(gnomeYawiPath, gnomeYawiIface+".GetWorkspaces", nil, &result); err != nil {
+		return nil, fmt.Errorf("listing workspaces needs yawi's extension ('yawi gnome install-extension'): %w", err)
+	}
+
+	var workspaces []gnomeWorkspace
+	if err := json.Unmarshal([]byte(result), &workspaces); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal workspaces: %w", err)
+	}
+
+	names := g.lookupWorkspaceNames()
+	infos := make([]*window.WorkspaceInfo, 0, len(workspaces))
+	for _, ws := range workspaces {
+		infos = append(infos, ws.toWorkspaceInfo(names))
+	}
+	return infos, nil
+}
+
+// ActiveWorkspace returns the workspace GNOME is showing
+func (g *GNOMEProvider) ActiveWorkspace() (*window.WorkspaceInfo, error) {
+	workspaces, err := g.ListWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	for _, ws := range workspaces {
+		if ws.Active {
+			return ws, nil
+		}
+	}
+	return nil, fmt.Errorf("no active workspace found in GNOME Shell")
+}
+
+// toWorkspaceInfo numbers workspaces from 1 like window workspaces, using configured names where set.
+// GNOME shows a workspace on every monitor, so the active one is the visible one.
+func (w *gnomeWorkspace) toWorkspaceInfo(names []string) *window.WorkspaceInfo {
+	name := gnomeWorkspaceName(w.Index, names)
+	if name == "" {
+		name = strconv.Itoa(w.Index + 1)
+	}
+	return &window.WorkspaceInfo{
+		ID:      w.Index + 1,
+		Name:    name,
+		Windows: w.Windows,
+		Visible: w.Active,
+		Active:  w.Active,
+	}
+}
@@ -1,12 +1,9 @@
 package providers
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
 	"fmt"
-	"net"
-	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/alde/yawi/pkg/window"
 )
@@ -19,82 +16,227 @@ func (h *HyprlandProvider) Name() string {
 	return "Hyprland"
 }
 
-// hyprlandWindow represents the JSON structure returned by Hyprland's activewindow command
+// hyprlandWindow represents the JSON structure returned by Hyprland's activewindow and clients commands
 type hyprlandWindow struct {
-	Address   string `json:"address"`
-	Mapped    bool   `json:"mapped"`
-	Hidden    bool   `json:"hidden"`
-	At        [2]int `json:"at"`
-	Size      [2]int `json:"size"`
+	Address   string   `json:"address"`
+	Mapped    flexBool `json:"mapped"`
+	Hidden    flexBool `json:"hidden"`
+	At        [2]int   `json:"at"`
+	Size      [2]int   `json:"size"`
 	Workspace struct {
 		ID   int    `json:"id"`
 		Name string `json:"name"`
 	} `json:"workspace"`
-	Floating       bool   `json:"floating"`
-	Monitor        int    `json:"monitor"`
-	Class          string `json:"class"`
-	Title          string `json:"title"`
-	InitialClass   string `json:"initialClass"`
-	InitialTitle   string `json:"initialTitle"`
-	PID            int    `json:"pid"`
-	XWayland       bool   `json:"xwayland"`
-	Pinned         bool   `json:"pinned"`
-	Fullscreen     bool   `json:"fullscreen"`
-	FakeFullscreen bool   `json:"fakeFullscreen"`
+	Floating     flexBool `json:"floating"`
+	Monitor      int      `json:"monitor"`
+	Class        string   `json:"class"`
+	Title        string   `json:"title"`
+	InitialClass string   `json:"initialClass"`
+	InitialTitle string   `json:"initialTitle"`
+	PID          int      `json:"pid"`
+	XWayland     flexBool `json:"xwayland"`
+	Pinned       flexBool `json:"pinned"`
+	// Fullscreen is a bool before Hyprland 0.42 and a fullscreen mode number after
+	Fullscreen     hyprlandFullscreen `json:"fullscreen"`
+	FakeFullscreen flexBool           `json:"fakeFullscreen"`
+
+	// Added in newer Hyprland releases
+	Grouped        []string `json:"grouped"`
+	Tags           []string `json:"tags"`
+	Swallowing     string   `json:"swallowing"`
+	FocusHistoryID int      `json:"focusHistoryID"`
+}
+
+// hyprlandFullscreen is a fullscreen mode: 0 none, 1 maximized, 2 fullscreen.
+// Before Hyprland 0.42 it was a bool, and true decodes as fullscreen.
+type hyprlandFullscreen flexInt
+
+const hyprlandFullscreenMode hyprlandFullscreen = 2
+
+func (f *hyprlandFullscreen) UnmarshalJSON(data []byte) error {
+	if string(bytes.TrimSpace(data)) == "true" {
+		*f = hyprlandFullscreenMode
+		return nil
+	}
+	return (*flexInt)(f).UnmarshalJSON(data)
 }
 
 // GetActiveWindow retrieves the currently active window from Hyprland
 func (h *HyprlandProvider) GetActiveWindow() (*window.WindowInfo, error) {
-	hyprSignature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
-	if hyprSignature == "" {
-		return nil, fmt.Errorf("HYPRLAND_INSTANCE_SIGNATURE not found - are we really running under Hyprland?")
+	client, err := newHyprlandClient()
+	if err != nil {
+		return nil, err
 	}
 
-	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
-	if runtimeDir == "" {
-		runtimeDir = "/tmp"
+	hyprWindow, err := client.activeWindow()
+	if err != nil {
+		return nil, err
+	}
+	if hyprWindow == nil {
+		return nil, fmt.Errorf("no active window found in Hyprland")
 	}
 
-	socketPath := filepath.Join(runtimeDir, "hypr", hyprSignature, ".socket2.sock")
+	return hyprWindow.toWindowInfo(client.monitorNames()), nil
+}
 
-	conn, err := net.Dial("unix", socketPath)
+// ListWindows returns every mapped Hyprland window
+func (h *HyprlandProvider) ListWindows() ([]*window.WindowInfo, error) {
+	client, err := newHyprlandClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Hyprland socket: %w", err)
+		return nil, err
 	}
-	defer conn.Close()
 
-	_, err = conn.Write([]byte("activewindow"))
+	clients, err := client.clients()
 	if err != nil {
-		return nil, fmt.Errorf("failed to send activewindow request: %w", err)
+		return nil, err
 	}
 
-	buffer := make([]byte, 4096)
-	n, err := conn.Read(buffer)
+	monitorNames := client.monitorNames()
+	windows := make([]*window.WindowInfo, 0, len(clients))
+	for i := range clients {
+		if !clients[i].Mapped {
+			continue
+		}
+		windows = append(windows, clients[i].toWindowInfo(monitorNames))
+	}
+	return windows, nil
+}
+
+// Events subscribes to Hyprland's event socket. The channel stays open across
+// Hyprland restarts and is closed once ctx is cancelled.
+func (h *HyprlandProvider) Events(ctx context.Context) (<-chan HyprlandEvent, error) {
+	client, err := newHyprlandClient()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Hyprland response: %w", err)
+		return nil, err
 	}
+	return client.events(ctx)
+}
 
-	response := strings.TrimSpace(string(buffer[:n]))
+// Watch reports active window changes driven by Hyprland's event socket
+func (h *HyprlandProvider) Watch(ctx context.Context) (<-chan window.Event, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return nil, err
+	}
 
-	if response == "Invalid" || response == "" {
-		return nil, fmt.Errorf("no active window found in Hyprland")
+	events, err := client.events(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hyprland always starts in the default submap; older releases can't be asked
+	mode, err := client.submap()
+	if err != nil {
+		mode = hyprlandDefaultSubmap
+	}
+
+	out := make(chan window.Event)
+	go func() {
+		defer close(out)
+
+		emitter := newWatchEmitter(ctx, out, mode)
+		refresh := func() bool {
+			hyprWindow, err := client.activeWindow()
+			if err != nil {
+				// Hyprland may be restarting; the next event retries
+				return true
+			}
+			var info *window.WindowInfo
+			if hyprWindow != nil {
+				info = hyprWindow.toWindowInfo(client.monitorNames())
+			}
+			return emitter.setWindow(info)
+		}
+
+		if !refresh() {
+			return
+		}
+		for event := range events {
+			keepGoing := true
+			switch e := event.(type) {
+			case HyprlandActiveWindowV2Event, HyprlandWindowTitleEvent, HyprlandMoveWindowEvent, HyprlandCloseWindowEvent,
+				HyprlandFullscreenEvent, HyprlandChangeFloatingModeEvent, HyprlandPinEvent:
+				keepGoing = refresh()
+			case HyprlandSubmapEvent:
+				keepGoing = emitter.setMode(hyprlandSubmapName(e.Name))
+			}
+			if !keepGoing {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// BindingMode returns the active submap. Only Hyprland releases that know the
+// submap request can answer this; use watch to follow submap events otherwise.
+func (h *HyprlandProvider) BindingMode() (string, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return "", err
 	}
+	return client.submap()
+}
 
-	var hyprWindow hyprlandWindow
-	if err := json.Unmarshal([]byte(response), &hyprWindow); err != nil {
-		return nil, fmt.Errorf("failed to decode Hyprland JSON response: %w", err)
+// CompositorVersion returns the Hyprland release, e.g. "v0.41.2"
+func (h *HyprlandProvider) CompositorVersion() (string, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return "", err
 	}
 
+	version, err := client.version()
+	if err != nil {
+		return "", err
+	}
+	return version.String(), nil
+}
+
+// toWindowInfo converts Hyprland's window JSON into the common window structure,
+// using monitorNames to turn the monitor ID into a connector name
+func (w *hyprlandWindow) toWindowInfo(monitorNames map[int]string) *window.WindowInfo {
 	// Use workspace name if available, otherwise fall back to ID
-	workspaceName := hyprWindow.Workspace.Name
+	workspaceName := w.Workspace.Name
 	if workspaceName == "" {
-		workspaceName = fmt.Sprintf("%d", hyprWindow.Workspace.ID)
+		workspaceName = fmt.Sprintf("%d", w.Workspace.ID)
+	}
+
+	monitor, ok := monitorNames[w.Monitor]
+	if !ok && w.Monitor >= 0 {
+		monitor = fmt.Sprintf("%d", w.Monitor)
+	}
+
+	// Hyprland reports "0x0" when nothing is being swallowed
+	swallowing := w.Swallowing
+	if swallowing == "0x0" {
+		swallowing = ""
 	}
 
 	return &window.WindowInfo{
-		Title:     hyprWindow.Title,
-		Class:     hyprWindow.Class,
-		PID:       hyprWindow.PID,
+		ID:        w.Address,
+		Title:     w.Title,
+		Class:     w.Class,
+		PID:       w.PID,
 		Workspace: workspaceName,
-	}, nil
-}
\ No newline at end of file
+		Monitor:   monitor,
+		Geometry: &window.Geometry{
+			X:      w.At[0],
+			Y:      w.At[1],
+			Width:  w.Size[0],
+			Height: w.Size[1],
+		},
+		Floating:   window.Bool(bool(w.Floating)),
+		Fullscreen: window.Bool(w.Fullscreen >= hyprlandFullscreenMode),
+		Pinned:     window.Bool(bool(w.Pinned)),
+		XWayland:   window.Bool(bool(w.XWayland)),
+		Hyprland: &window.HyprlandDetails{
+			Address:        w.Address,
+			InitialClass:   w.InitialClass,
+			InitialTitle:   w.InitialTitle,
+			FocusHistoryID: w.FocusHistoryID,
+			Grouped:        w.Grouped,
+			Tags:           w.Tags,
+			Swallowing:     swallowing,
+		},
+	}
+}
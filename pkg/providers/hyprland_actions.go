@@ -0,0 +1,105 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dispatch runs a Hyprland dispatcher. Hyprland answers "ok" on success and
+// with a human readable message otherwise, which becomes the error text.
+func (c *hyprlandClient) dispatch(dispatcher, args string) error {
+	response, err := c.request(strings.TrimSpace("dispatch " + dispatcher + " " + args))
+	if err != nil {
+		return err
+	}
+
+	reply := strings.TrimSpace(string(response))
+	if reply != "ok" {
+		return fmt.Errorf("hyprland dispatcher %s failed: %s", dispatcher, reply)
+	}
+	return nil
+}
+
+// hyprlandWindowSelector turns a window address into a dispatcher window argument
+func hyprlandWindowSelector(id string) (string, error) {
+	if id == "" {
+		return "", fmt.Errorf("no window address given")
+	}
+	return "address:" + hyprlandAddress(id), nil
+}
+
+// dispatchOnWindow runs a dispatcher that takes the target window as its argument
+func (h *HyprlandProvider) dispatchOnWindow(dispatcher, id string) error {
+	selector, err := hyprlandWindowSelector(id)
+	if err != nil {
+		return err
+	}
+
+	client, err := newHyprlandClient()
+	if err != nil {
+		return err
+	}
+	return client.dispatch(dispatcher, selector)
+}
+
+// moveToWorkspace runs one of the movetoworkspace dispatchers, which take "WORKSPACE,WINDOW"
+func (h *HyprlandProvider) moveToWorkspace(dispatcher, id, workspace string) error {
+	if workspace == "" {
+		return fmt.Errorf("no workspace given")
+	}
+
+	selector, err := hyprlandWindowSelector(id)
+	if err != nil {
+		return err
+	}
+
+	client, err := newHyprlandClient()
+	if err != nil {
+		return err
+	}
+	return client.dispatch(dispatcher, workspace+","+selector)
+}
+
+// FocusWindow focuses the window with the given address
+func (h *HyprlandProvider) FocusWindow(id string) error {
+	return h.dispatchOnWindow("focuswindow", id)
+}
+
+// CloseWindow closes the window with the given address
+func (h *HyprlandProvider) CloseWindow(id string) error {
+	return h.dispatchOnWindow("closewindow", id)
+}
+
+// MoveWindowToWorkspace moves the window to a workspace and follows it
+func (h *HyprlandProvider) MoveWindowToWorkspace(id, workspace string) error {
+	return h.moveToWorkspace("movetoworkspace", id, workspace)
+}
+
+// MoveWindowToWorkspaceSilent moves the window to a workspace without following it
+func (h *HyprlandProvider) MoveWindowToWorkspaceSilent(id, workspace string) error {
+	return h.moveToWorkspace("movetoworkspacesilent", id, workspace)
+}
+
+// ToggleFloating switches the window between tiled and floating
+func (h *HyprlandProvider) ToggleFloating(id string) error {
+	return h.dispatchOnWindow("togglefloating", id)
+}
+
+// TogglePin pins a floating window to all workspaces, or unpins it
+func (h *HyprlandProvider) TogglePin(id string) error {
+	return h.dispatchOnWindow("pin", id)
+}
+
+// ToggleFullscreen toggles fullscreen on the window. Hyprland's fullscreen
+// dispatcher only acts on the focused window, so the window is focused first.
+func (h *HyprlandProvider) ToggleFullscreen(id string) error {
+	if err := h.FocusWindow(id); err != nil {
+		return err
+	}
+
+	client, err := newHyprlandClient()
+	if err != nil {
+		return err
+	}
+	return client.dispatch("fullscreen", "0")
+}
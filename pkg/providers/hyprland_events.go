@@ -0,0 +1,240 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// HyprlandEvent is an event read from Hyprland's event socket (.socket2.sock)
+type HyprlandEvent interface {
+	// EventName returns the raw event name, e.g. "activewindow"
+	EventName() string
+}
+
+// HyprlandActiveWindowEvent is sent when focus moves to another window (activewindow)
+type HyprlandActiveWindowEvent struct {
+	Class string
+	Title string
+}
+
+// HyprlandActiveWindowV2Event carries the address of the newly focused window (activewindowv2)
+type HyprlandActiveWindowV2Event struct {
+	Address string
+}
+
+// HyprlandWorkspaceEvent is sent when the active workspace changes (workspace)
+type HyprlandWorkspaceEvent struct {
+	Name string
+}
+
+// HyprlandFocusedMonitorEvent is sent when the focused monitor changes (focusedmon)
+type HyprlandFocusedMonitorEvent struct {
+	Monitor   string
+	Workspace string
+}
+
+// HyprlandOpenWindowEvent is sent when a window is mapped (openwindow)
+type HyprlandOpenWindowEvent struct {
+	Address   string
+	Workspace string
+	Class     string
+	Title     string
+}
+
+// HyprlandCloseWindowEvent is sent when a window is unmapped (closewindow)
+type HyprlandCloseWindowEvent struct {
+	Address string
+}
+
+// HyprlandMoveWindowEvent is sent when a window changes workspace (movewindow)
+type HyprlandMoveWindowEvent struct {
+	Address   string
+	Workspace string
+}
+
+// HyprlandWindowTitleEvent is sent when a window changes its title (windowtitle)
+type HyprlandWindowTitleEvent struct {
+	Address string
+}
+
+// HyprlandUrgentEvent is sent when a window requests attention (urgent)
+type HyprlandUrgentEvent struct {
+	Address string
+}
+
+// HyprlandFullscreenEvent is sent when the active window enters or leaves fullscreen (fullscreen)
+type HyprlandFullscreenEvent struct {
+	Fullscreen bool
+}
+
+// HyprlandChangeFloatingModeEvent is sent when a window starts or stops floating (changefloatingmode)
+type HyprlandChangeFloatingModeEvent struct {
+	Address  string
+	Floating bool
+}
+
+// HyprlandPinEvent is sent when a window is pinned or unpinned (pin)
+type HyprlandPinEvent struct {
+	Address string
+	Pinned  bool
+}
+
+// HyprlandSubmapEvent is sent when the keybind submap changes; Name is empty for the default map (submap)
+type HyprlandSubmapEvent struct {
+	Name string
+}
+
+// HyprlandRawEvent holds any event yawi doesn't decode further
+type HyprlandRawEvent struct {
+	Name string
+	Data string
+}
+
+func (HyprlandActiveWindowEvent) EventName() string       { return "activewindow" }
+func (HyprlandActiveWindowV2Event) EventName() string     { return "activewindowv2" }
+func (HyprlandWorkspaceEvent) EventName() string          { return "workspace" }
+func (HyprlandFocusedMonitorEvent) EventName() string     { return "focusedmon" }
+func (HyprlandOpenWindowEvent) EventName() string         { return "openwindow" }
+func (HyprlandCloseWindowEvent) EventName() string        { return "closewindow" }
+func (HyprlandMoveWindowEvent) EventName() string         { return "movewindow" }
+func (HyprlandWindowTitleEvent) EventName() string        { return "windowtitle" }
+func (HyprlandUrgentEvent) EventName() string             { return "urgent" }
+func (HyprlandFullscreenEvent) EventName() string         { return "fullscreen" }
+func (HyprlandChangeFloatingModeEvent) EventName() string { return "changefloatingmode" }
+func (HyprlandPinEvent) EventName() string                { return "pin" }
+func (HyprlandSubmapEvent) EventName() string             { return "submap" }
+func (e HyprlandRawEvent) EventName() string              { return e.Name }
+
+const (
+	hyprlandReconnectMin = 250 * time.Millisecond
+	hyprlandReconnectMax = 5 * time.Second
+)
+
+// parseHyprlandEvent decodes a single EVENT>>DATA line. Fields are split from
+// the left only as far as needed, so titles containing commas stay intact.
+func parseHyprlandEvent(line string) HyprlandEvent {
+	name, data, _ := strings.Cut(line, ">>")
+
+	switch name {
+	case "activewindow":
+		class, title, _ := strings.Cut(data, ",")
+		return HyprlandActiveWindowEvent{Class: class, Title: title}
+	case "activewindowv2":
+		return HyprlandActiveWindowV2Event{Address: hyprlandAddress(data)}
+	case "workspace":
+		return HyprlandWorkspaceEvent{Name: data}
+	case "focusedmon":
+		monitor, workspace, _ := strings.Cut(data, ",")
+		return HyprlandFocusedMonitorEvent{Monitor: monitor, Workspace: workspace}
+	case "openwindow":
+		fields := strings.SplitN(data, ",", 4)
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		return HyprlandOpenWindowEvent{
+			Address:   hyprlandAddress(fields[0]),
+			Workspace: fields[1],
+			Class:     fields[2],
+			Title:     fields[3],
+		}
+	case "closewindow":
+		return HyprlandCloseWindowEvent{Address: hyprlandAddress(data)}
+	case "movewindow":
+		address, workspace, _ := strings.Cut(data, ",")
+		return HyprlandMoveWindowEvent{Address: hyprlandAddress(address), Workspace: workspace}
+	case "windowtitle":
+		return HyprlandWindowTitleEvent{Address: hyprlandAddress(data)}
+	case "urgent":
+		return HyprlandUrgentEvent{Address: hyprlandAddress(data)}
+	case "fullscreen":
+		return HyprlandFullscreenEvent{Fullscreen: data == "1"}
+	case "changefloatingmode":
+		address, floating, _ := strings.Cut(data, ",")
+		return HyprlandChangeFloatingModeEvent{Address: hyprlandAddress(address), Floating: floating == "1"}
+	case "pin":
+		address, pinned, _ := strings.Cut(data, ",")
+		return HyprlandPinEvent{Address: hyprlandAddress(address), Pinned: pinned == "1"}
+	case "submap":
+		return HyprlandSubmapEvent{Name: data}
+	default:
+		return HyprlandRawEvent{Name: name, Data: data}
+	}
+}
+
+// hyprlandAddress normalizes event addresses, which Hyprland sends without
+// the 0x prefix used in its JSON replies
+func hyprlandAddress(address string) string {
+	if address == "" || strings.HasPrefix(address, "0x") {
+		return address
+	}
+	return "0x" + address
+}
+
+// events streams parsed events until ctx is cancelled. If Hyprland goes away
+// the stream keeps retrying the socket with backoff instead of closing.
+func (c *hyprlandClient) events(ctx context.Context) (<-chan HyprlandEvent, error) {
+	conn, err := net.Dial("unix", c.eventSocket())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Hyprland event socket: %w", err)
+	}
+
+	ch := make(chan HyprlandEvent)
+	go func() {
+		defer close(ch)
+		for conn != nil {
+			c.readEvents(ctx, conn, ch)
+			conn.Close()
+			conn = c.reconnect(ctx)
+		}
+	}()
+	return ch, nil
+}
+
+// readEvents forwards events from one connection until it breaks or ctx ends
+func (c *hyprlandClient) readEvents(ctx context.Context, conn net.Conn, ch chan<- HyprlandEvent) {
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		select {
+		case ch <- parseHyprlandEvent(line):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// reconnect waits for the event socket to come back, returning nil once ctx is done
+func (c *hyprlandClient) reconnect(ctx context.Context) net.Conn {
+	delay := hyprlandReconnectMin
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(delay):
+		}
+
+		// The instance directory may have moved, e.g. from /tmp to XDG_RUNTIME_DIR
+		if dir, err := hyprlandSocketDir(); err == nil {
+			reconnected := &hyprlandClient{socketDir: dir}
+			if conn, err := net.Dial("unix", reconnected.eventSocket()); err == nil {
+				return conn
+			}
+		}
+
+		delay *= 2
+		if delay > hyprlandReconnectMax {
+			delay = hyprlandReconnectMax
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package providers
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseHyprlandEvent(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected HyprlandEvent
+	}{
+		{"activewindow>>kitty,vim, main.go", HyprlandActiveWindowEvent{Class: "kitty", Title: "vim, main.go"}},
+		{"activewindowv2>>5632a2f0e9a0", HyprlandActiveWindowV2Event{Address: "0x5632a2f0e9a0"}},
+		{"activewindowv2>>", HyprlandActiveWindowV2Event{}},
+		{"workspace>>2", HyprlandWorkspaceEvent{Name: "2"}},
+		{"focusedmon>>DP-1,3", HyprlandFocusedMonitorEvent{Monitor: "DP-1", Workspace: "3"}},
+		{"openwindow>>80e62df0,2,firefox,Hello, world", HyprlandOpenWindowEvent{Address: "0x80e62df0", Workspace: "2", Class: "firefox", Title: "Hello, world"}},
+		{"closewindow>>80e62df0", HyprlandCloseWindowEvent{Address: "0x80e62df0"}},
+		{"movewindow>>80e62df0,special:scratch", HyprlandMoveWindowEvent{Address: "0x80e62df0", Workspace: "special:scratch"}},
+		{"windowtitle>>80e62df0", HyprlandWindowTitleEvent{Address: "0x80e62df0"}},
+		{"urgent>>80e62df0", HyprlandUrgentEvent{Address: "0x80e62df0"}},
+		{"fullscreen>>1", HyprlandFullscreenEvent{Fullscreen: true}},
+		{"fullscreen>>0", HyprlandFullscreenEvent{Fullscreen: false}},
+		{"changefloatingmode>>80e62df0,1", HyprlandChangeFloatingModeEvent{Address: "0x80e62df0", Floating: true}},
+		{"pin>>80e62df0,0", HyprlandPinEvent{Address: "0x80e62df0", Pinned: false}},
+		{"submap>>resize", HyprlandSubmapEvent{Name: "resize"}},
+		{"submap>>", HyprlandSubmapEvent{}},
+		{"configreloaded>>", HyprlandRawEvent{Name: "configreloaded"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			result := parseHyprlandEvent(tt.line)
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("parseHyprlandEvent(%q) = %#v, want %#v", tt.line, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHyprlandEvents_Reconnect(t *testing.T) {
+	fake := newFakeHyprland(t, nil)
+	eventPath := filepath.Join(fake.dir, ".socket2.sock")
+
+	listener, err := net.Listen("unix", eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	provider := &HyprlandProvider{}
+	events, err := provider.Events(ctx)
+	if err != nil {
+		t.Fatalf("Events() error: %v", err)
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(conn, "workspace>>1\n")
+	if event := <-events; event != (HyprlandWorkspaceEvent{Name: "1"}) {
+		t.Errorf("first event = %#v", event)
+	}
+
+	// Simulate Hyprland restarting: drop the connection and recreate the socket
+	conn.Close()
+	listener.Close()
+	listener, err = net.Listen("unix", eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err = listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "submap>>resize\n")
+	if event := <-events; event != (HyprlandSubmapEvent{Name: "resize"}) {
+		t.Errorf("event after reconnect = %#v", event)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestHyprlandProvider_WatchSubmap(t *testing.T) {
+	fake := newFakeHyprland(t, map[string]string{
+		"submap":         "\n",
+		"j/activewindow": `{"address":"0x1","class":"kitty","title":"shell","workspace":{"id":1,"name":"1"}}`,
+	})
+
+	listener, err := net.Listen("unix", filepath.Join(fake.dir, ".socket2.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider := &HyprlandProvider{}
+	events, err := provider.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+
+	first := <-events
+	if first.Mode != "default" || first.Window == nil || first.Window.Class != "kitty" {
+		t.Fatalf("first event = %+v", first)
+	}
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	io.WriteString(conn, "submap>>resize\nsubmap>>\n")
+
+	if event := <-events; event.Mode != "resize" || !reflect.DeepEqual(event.Window, first.Window) {
+		t.Errorf("second event = %+v", event)
+	}
+	if event := <-events; event.Mode != "default" {
+		t.Errorf("third event = %+v", event)
+	}
+}
+
+func TestHyprlandProvider_WatchStateChanges(t *testing.T) {
+	fake := newFakeHyprland(t, map[string]string{
+		"submap":         "\n",
+		"j/activewindow": `{"address":"0x1","class":"kitty","title":"shell","workspace":{"id":1,"name":"1"}}`,
+	})
+
+	listener, err := net.Listen("unix", filepath.Join(fake.dir, ".socket2.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := (&HyprlandProvider{}).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	<-events
+
+	conn, err := listener.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Each state change of the focused window re-reads it
+	for _, line := range []string{"fullscreen>>1", "changefloatingmode>>1,1", "pin>>1,1"} {
+		drainHyprlandRequests(fake)
+		io.WriteString(conn, line+"\n")
+		if !awaitHyprlandRequest(ctx, fake, "j/activewindow") {
+			t.Errorf("%s didn't refresh the active window", line)
+		}
+	}
+}
+
+// drainHyprlandRequests forgets the requests seen so far
+func drainHyprlandRequests(f *fakeHyprland) {
+	for {
+		select {
+		case <-f.requests:
+		default:
+			return
+		}
+	}
+}
+
+// awaitHyprlandRequest waits for request to reach f
+func awaitHyprlandRequest(ctx context.Context, f *fakeHyprland, request string) bool {
+	for {
+		select {
+		case got := <-f.requests:
+			if got == request {
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
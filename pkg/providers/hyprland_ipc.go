@@ -0,0 +1,255 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hyprlandClient talks to Hyprland's request socket (.socket.sock). Every
+// request opens a fresh connection, since Hyprland closes the socket after
+// writing its reply.
+type hyprlandClient struct {
+	socketDir string
+}
+
+// hyprlandWorkspace represents the JSON structure returned by Hyprland's workspaces command
+type hyprlandWorkspace struct {
+	ID              int      `json:"id"`
+	Name            string   `json:"name"`
+	Monitor         string   `json:"monitor"`
+	MonitorID       int      `json:"monitorID"`
+	Windows         int      `json:"windows"`
+	HasFullscreen   flexBool `json:"hasfullscreen"`
+	LastWindow      string   `json:"lastwindow"`
+	LastWindowTitle string   `json:"lastwindowtitle"`
+}
+
+// hyprlandMonitor represents the JSON structure returned by Hyprland's monitors command
+type hyprlandMonitor struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	Make            string  `json:"make"`
+	Model           string  `json:"model"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	RefreshRate     float64 `json:"refreshRate"`
+	X               int     `json:"x"`
+	Y               int     `json:"y"`
+	ActiveWorkspace struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"activeWorkspace"`
+	SpecialWorkspace struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"specialWorkspace"`
+	Scale      float64  `json:"scale"`
+	Transform  int      `json:"transform"`
+	Focused    flexBool `json:"focused"`
+	DPMSStatus flexBool `json:"dpmsStatus"`
+	VRR        flexBool `json:"vrr"`
+	Disabled   flexBool `json:"disabled"`
+}
+
+// hyprlandVersion represents the JSON structure returned by Hyprland's version command
+type hyprlandVersion struct {
+	Branch        string   `json:"branch"`
+	Commit        string   `json:"commit"`
+	Dirty         flexBool `json:"dirty"`
+	CommitMessage string   `json:"commit_message"`
+	CommitDate    string   `json:"commit_date"`
+	Tag           string   `json:"tag"`
+	Commits       string   `json:"commits"`
+	Flags         []string `json:"flags"`
+}
+
+// newHyprlandClient locates the socket directory of the running Hyprland instance
+func newHyprlandClient() (*hyprlandClient, error) {
+	dir, err := hyprlandSocketDir()
+	if err != nil {
+		return nil, err
+	}
+	return &hyprlandClient{socketDir: dir}, nil
+}
+
+// hyprlandSocketDir finds the instance directory holding Hyprland's sockets.
+// Hyprland 0.40+ uses $XDG_RUNTIME_DIR/hypr, older releases used /tmp/hypr.
+func hyprlandSocketDir() (string, error) {
+	signature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
+	if signature == "" {
+		return "", fmt.Errorf("HYPRLAND_INSTANCE_SIGNATURE not found - are we really running under Hyprland?")
+	}
+
+	var candidates []string
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		candidates = append(candidates, filepath.Join(runtimeDir, "hypr", signature))
+	}
+	candidates = append(candidates, filepath.Join("/tmp", "hypr", signature))
+
+	for _, dir := range candidates {
+		if _, err := os.Stat(filepath.Join(dir, ".socket.sock")); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("no Hyprland socket found (looked in %s)", strings.Join(candidates, ", "))
+}
+
+// commandSocket returns the path of the request/response socket
+func (c *hyprlandClient) commandSocket() string {
+	return filepath.Join(c.socketDir, ".socket.sock")
+}
+
+// eventSocket returns the path of the event broadcast socket
+func (c *hyprlandClient) eventSocket() string {
+	return filepath.Join(c.socketDir, ".socket2.sock")
+}
+
+// request sends a raw command and reads the whole reply
+func (c *hyprlandClient) request(command string) ([]byte, error) {
+	conn, err := net.Dial("unix", c.commandSocket())
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Hyprland socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(command)); err != nil {
+		return nil, fmt.Errorf("failed to send %q request: %w", command, err)
+	}
+
+	response, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Hyprland response: %w", err)
+	}
+	return response, nil
+}
+
+// requestJSON sends a command with the j/ prefix and decodes the reply into v
+func (c *hyprlandClient) requestJSON(command string, v any) error {
+	response, err := c.request("j/" + command)
+	if err != nil {
+		return err
+	}
+
+	trimmed := strings.TrimSpace(string(response))
+	if trimmed == "unknown request" {
+		return fmt.Errorf("unknown Hyprland request %q", command)
+	}
+
+	if err := json.Unmarshal(response, v); err != nil {
+		return fmt.Errorf("failed to decode Hyprland %s response: %w", command, err)
+	}
+	return nil
+}
+
+// activeWindow returns the focused window, or nil when nothing has focus
+func (c *hyprlandClient) activeWindow() (*hyprlandWindow, error) {
+	response, err := c.request("j/activewindow")
+	if err != nil {
+		return nil, err
+	}
+
+	// Older releases answer "Invalid", newer ones an empty object
+	trimmed := strings.TrimSpace(string(response))
+	if trimmed == "Invalid" || trimmed == "" || trimmed == "{}" {
+		return nil, nil
+	}
+
+	var hyprWindow hyprlandWindow
+	if err := json.Unmarshal([]byte(trimmed), &hyprWindow); err != nil {
+		return nil, fmt.Errorf("failed to decode Hyprland JSON response: %w", err)
+	}
+	return &hyprWindow, nil
+}
+
+// clients returns every window Hyprland knows about
+func (c *hyprlandClient) clients() ([]hyprlandWindow, error) {
+	var windows []hyprlandWindow
+	if err := c.requestJSON("clients", &windows); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// workspaces returns all existing workspaces
+func (c *hyprlandClient) workspaces() ([]hyprlandWorkspace, error) {
+	var workspaces []hyprlandWorkspace
+	if err := c.requestJSON("workspaces", &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// activeWorkspace returns the focused workspace
+func (c *hyprlandClient) activeWorkspace() (*hyprlandWorkspace, error) {
+	var workspace hyprlandWorkspace
+	if err := c.requestJSON("activeworkspace", &workspace); err != nil {
+		return nil, err
+	}
+	return &workspace, nil
+}
+
+// monitors returns all connected monitors
+func (c *hyprlandClient) monitors() ([]hyprlandMonitor, error) {
+	var monitors []hyprlandMonitor
+	if err := c.requestJSON("monitors", &monitors); err != nil {
+		return nil, err
+	}
+	return monitors, nil
+}
+
+// hyprlandDefaultSubmap is how yawi names the submap that is active when no other is
+const hyprlandDefaultSubmap = "default"
+
+// hyprlandSubmapName maps Hyprland's empty submap name to "default", like Sway's binding modes
+func hyprlandSubmapName(name string) string {
+	if name == "" {
+		return hyprlandDefaultSubmap
+	}
+	return name
+}
+
+// submap returns the active submap
+func (c *hyprlandClient) submap() (string, error) {
+	response, err := c.request("submap")
+	if err != nil {
+		return "", err
+	}
+
+	reply := strings.TrimSpace(string(response))
+	if reply == "unknown request" {
+		return "", fmt.Errorf("this Hyprland version can't report the active submap; 'yawi watch --json' follows submap changes instead")
+	}
+	return hyprlandSubmapName(reply), nil
+}
+
+// String returns the release tag, falling back to the commit for untagged builds
+func (v *hyprlandVersion) String() string {
+	version := v.Tag
+	if version == "" {
+		commit := v.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		version = "git-" + commit
+	}
+	if v.Dirty {
+		version += " (dirty)"
+	}
+	return version
+}
+
+// version returns build information about the running Hyprland
+func (c *hyprlandClient) version() (*hyprlandVersion, error) {
+	var v hyprlandVersion
+	if err := c.requestJSON("version", &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
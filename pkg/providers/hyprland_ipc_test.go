@@ -0,0 +1,250 @@
+package providers
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeHyprland serves canned replies on a .socket.sock inside a temporary
+// XDG_RUNTIME_DIR and records the requests it receives
+type fakeHyprland struct {
+	dir      string
+	replies  map[string]string
+	requests chan string
+}
+
+func newFakeHyprland(t *testing.T, replies map[string]string) *fakeHyprland {
+	t.Helper()
+
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "test")
+
+	dir := filepath.Join(runtimeDir, "hypr", "test")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := net.Listen("unix", filepath.Join(dir, ".socket.sock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeHyprland{dir: dir, replies: replies, requests: make(chan string, 32)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			buf := make([]byte, 1024)
+			n, _ := conn.Read(buf)
+			request := string(buf[:n])
+			f.requests <- request
+			io.WriteString(conn, f.replies[request])
+			conn.Close()
+		}
+	}()
+	return f
+}
+
+func TestHyprlandClient_ActiveWindowLongTitle(t *testing.T) {
+	longTitle := strings.Repeat("a very long title ", 1000)
+	newFakeHyprland(t, map[string]string{
+		"j/activewindow": `{"address":"0x1","class":"kitty","title":"` + longTitle + `","pid":42,"workspace":{"id":3,"name":"3"}}`,
+	})
+
+	provider := &HyprlandProvider{}
+	info, err := provider.GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Title != longTitle {
+		t.Errorf("title truncated to %d bytes, want %d", len(info.Title), len(longTitle))
+	}
+	if info.Class != "kitty" || info.PID != 42 || info.Workspace != "3" {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestHyprlandClient_NoActiveWindow(t *testing.T) {
+	for _, reply := range []string{"Invalid", "{}", ""} {
+		newFakeHyprland(t, map[string]string{"j/activewindow": reply})
+
+		client, err := newHyprlandClient()
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := client.activeWindow()
+		if err != nil {
+			t.Errorf("reply %q: unexpected error %v", reply, err)
+		}
+		if w != nil {
+			t.Errorf("reply %q: expected no window, got %+v", reply, w)
+		}
+	}
+}
+
+func TestHyprlandClient_TypedRequests(t *testing.T) {
+	fake := newFakeHyprland(t, map[string]string{
+		"j/clients":    `[{"address":"0x1","class":"a"},{"address":"0x2","class":"b"}]`,
+		"j/workspaces": `[{"id":1,"name":"1","monitor":"DP-1","windows":2}]`,
+		"j/monitors":   `[{"id":0,"name":"DP-1","width":2560,"height":1440,"scale":1.25,"focused":true}]`,
+		"j/version":    `{"branch":"main","tag":"v0.41.2"}`,
+		"j/bogus":      "unknown request",
+	})
+
+	client, err := newHyprlandClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.socketDir != fake.dir {
+		t.Errorf("socketDir = %q, want %q", client.socketDir, fake.dir)
+	}
+
+	clients, err := client.clients()
+	if err != nil || len(clients) != 2 || clients[1].Address != "0x2" {
+		t.Errorf("clients() = %+v, %v", clients, err)
+	}
+
+	workspaces, err := client.workspaces()
+	if err != nil || len(workspaces) != 1 || workspaces[0].Monitor != "DP-1" {
+		t.Errorf("workspaces() = %+v, %v", workspaces, err)
+	}
+
+	monitors, err := client.monitors()
+	if err != nil || len(monitors) != 1 || monitors[0].Scale != 1.25 || !monitors[0].Focused {
+		t.Errorf("monitors() = %+v, %v", monitors, err)
+	}
+
+	version, err := client.version()
+	if err != nil || version.Tag != "v0.41.2" {
+		t.Errorf("version() = %+v, %v", version, err)
+	}
+
+	var discard any
+	if err := client.requestJSON("bogus", &discard); err == nil {
+		t.Error("expected error for unknown request")
+	}
+}
+
+func TestHyprlandSocketDir_TmpFallback(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	t.Setenv("HYPRLAND_INSTANCE_SIGNATURE", "does-not-exist")
+
+	_, err := hyprlandSocketDir()
+	if err == nil {
+		t.Fatal("expected error when no socket exists")
+	}
+	if !strings.Contains(err.Error(), "/tmp/hypr/does-not-exist") {
+		t.Errorf("error should list the /tmp fallback, got: %v", err)
+	}
+}
+
+func TestHyprlandProvider_ListWindows(t *testing.T) {
+	newFakeHyprland(t, map[string]string{
+		"j/clients": `[
+			{"address":"0x1","mapped":true,"class":"kitty","title":"~","pid":10,"workspace":{"id":1,"name":"1"}},
+			{"address":"0x2","mapped":false,"class":"ghost","workspace":{"id":-1,"name":""}},
+			{"address":"0x3","mapped":true,"class":"firefox","title":"Docs","pid":11,"workspace":{"id":-98,"name":"special:scratch"}}
+		]`,
+	})
+
+	provider := &HyprlandProvider{}
+	windows, err := provider.ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 mapped windows, got %d", len(windows))
+	}
+	if windows[0].Class != "kitty" || windows[0].Workspace != "1" {
+		t.Errorf("unexpected first window: %+v", windows[0])
+	}
+	if windows[1].Class != "firefox" || windows[1].Workspace != "special:scratch" {
+		t.Errorf("unexpected second window: %+v", windows[1])
+	}
+}
+
+func TestHyprlandProvider_Dispatch(t *testing.T) {
+	fake := newFakeHyprland(t, map[string]string{
+		"dispatch focuswindow address:0x1a2b":             "ok",
+		"dispatch movetoworkspacesilent 3,address:0x1a2b": "ok",
+		"dispatch togglefloating address:0xdead":          "Window not found",
+		"dispatch fullscreen 0":                           "ok",
+		"dispatch pin address:0x1a2b":                     "ok",
+	})
+
+	provider := &HyprlandProvider{}
+
+	if err := provider.FocusWindow("1a2b"); err != nil {
+		t.Errorf("FocusWindow() error: %v", err)
+	}
+	if got := <-fake.requests; got != "dispatch focuswindow address:0x1a2b" {
+		t.Errorf("unexpected request %q", got)
+	}
+
+	if err := provider.MoveWindowToWorkspaceSilent("0x1a2b", "3"); err != nil {
+		t.Errorf("MoveWindowToWorkspaceSilent() error: %v", err)
+	}
+	<-fake.requests
+
+	err := provider.ToggleFloating("0xdead")
+	if err == nil || !strings.Contains(err.Error(), "Window not found") {
+		t.Errorf("ToggleFloating() should surface the dispatcher error, got %v", err)
+	}
+	<-fake.requests
+
+	if err := provider.ToggleFullscreen("0x1a2b"); err != nil {
+		t.Errorf("ToggleFullscreen() error: %v", err)
+	}
+	if first, second := <-fake.requests, <-fake.requests; first != "dispatch focuswindow address:0x1a2b" || second != "dispatch fullscreen 0" {
+		t.Errorf("ToggleFullscreen() sent %q, %q", first, second)
+	}
+
+	if err := provider.CloseWindow(""); err == nil {
+		t.Error("CloseWindow() should reject an empty address")
+	}
+}
+
+func TestHyprlandProvider_BindingMode(t *testing.T) {
+	tests := []struct {
+		reply   string
+		want    string
+		wantErr bool
+	}{
+		{reply: "resize\n", want: "resize"},
+		{reply: "", want: "default"},
+		{reply: "unknown request", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		newFakeHyprland(t, map[string]string{"submap": tt.reply})
+
+		mode, err := (&HyprlandProvider{}).BindingMode()
+		if (err != nil) != tt.wantErr || mode != tt.want {
+			t.Errorf("reply %q: BindingMode() = %q, %v", tt.reply, mode, err)
+		}
+	}
+}
+
+func TestHyprlandVersion_String(t *testing.T) {
+	tests := []struct {
+		version hyprlandVersion
+		want    string
+	}{
+		{hyprlandVersion{Tag: "v0.41.2", Commit: "918d8340afd652b011b937d29d5eea0be08467f5"}, "v0.41.2"},
+		{hyprlandVersion{Commit: "918d8340afd652b011b937d29d5eea0be08467f5"}, "git-918d834"},
+		{hyprlandVersion{Tag: "v0.45.0", Dirty: true}, "v0.45.0 (dirty)"},
+	}
+	for _, tt := range tests {
+		if got := tt.version.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
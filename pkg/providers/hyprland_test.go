@@ -0,0 +1,101 @@
+package providers
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+func TestHyprlandWindow_ToWindowInfo(t *testing.T) {
+	payload := `{
+		"address": "0x5632a2f0e9a0",
+		"mapped": true,
+		"hidden": false,
+		"at": [1290, 60],
+		"size": [1250, 1352],
+		"workspace": {"id": 2, "name": "2"},
+		"floating": true,
+		"monitor": 0,
+		"class": "org.wezfurlong.wezterm",
+		"title": "nvim",
+		"initialClass": "org.wezfurlong.wezterm",
+		"initialTitle": "wezterm",
+		"pid": 4321,
+		"xwayland": false,
+		"pinned": true,
+		"fullscreen": false,
+		"fakeFullscreen": false,
+		"grouped": ["0x5632a2f0e9a0", "0x5632a2f11111"],
+		"tags": ["work"],
+		"swallowing": "0x0",
+		"focusHistoryID": 1
+	}`
+
+	var w hyprlandWindow
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		t.Fatalf("failed to decode window: %v", err)
+	}
+
+	info := w.toWindowInfo(map[int]string{0: "DP-1"})
+	expected := &window.WindowInfo{
+		ID:         "0x5632a2f0e9a0",
+		Title:      "nvim",
+		Class:      "org.wezfurlong.wezterm",
+		PID:        4321,
+		Workspace:  "2",
+		Monitor:    "DP-1",
+		Geometry:   &window.Geometry{X: 1290, Y: 60, Width: 1250, Height: 1352},
+		Floating:   window.Bool(true),
+		Fullscreen: window.Bool(false),
+		Pinned:     window.Bool(true),
+		XWayland:   window.Bool(false),
+		Hyprland: &window.HyprlandDetails{
+			Address:        "0x5632a2f0e9a0",
+			InitialClass:   "org.wezfurlong.wezterm",
+			InitialTitle:   "wezterm",
+			FocusHistoryID: 1,
+			Grouped:        []string{"0x5632a2f0e9a0", "0x5632a2f11111"},
+			Tags:           []string{"work"},
+		},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("toWindowInfo() = %+v\nwant %+v", info, expected)
+	}
+}
+
+func TestHyprlandWindow_MonitorName(t *testing.T) {
+	w := hyprlandWindow{Monitor: 1}
+	if got := w.toWindowInfo(map[int]string{1: "HDMI-A-1"}).Monitor; got != "HDMI-A-1" {
+		t.Errorf("Monitor = %q, want HDMI-A-1", got)
+	}
+	if got := w.toWindowInfo(nil).Monitor; got != "1" {
+		t.Errorf("Monitor without names = %q, want 1", got)
+	}
+}
+
+func TestHyprlandWindow_FullscreenAcrossVersions(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    bool
+	}{
+		{"bool before 0.42", `{"address":"0x1","fullscreen":true,"fullscreenMode":0}`, true},
+		{"mode after 0.42", `{"address":"0x1","fullscreen":2,"fullscreenClient":2}`, true},
+		{"maximized after 0.42", `{"address":"0x1","fullscreen":1,"fullscreenClient":1}`, false},
+		{"not fullscreen", `{"address":"0x1","fullscreen":0,"fullscreenClient":0}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var w hyprlandWindow
+			if err := json.Unmarshal([]byte(tt.payload), &w); err != nil {
+				t.Fatalf("failed to decode window: %v", err)
+			}
+			if got := *w.toWindowInfo(nil).Fullscreen; got != tt.want {
+				t.Errorf("Fullscreen = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
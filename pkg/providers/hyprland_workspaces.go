@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// ListWorkspaces returns every Hyprland workspace, marking the focused one and
+// the ones currently shown on a monitor
+func (h *HyprlandProvider) ListWorkspaces() ([]*window.WorkspaceInfo, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces, err := client.workspaces()
+	if err != nil {
+		return nil, err
+	}
+
+	monitors, err := client.monitors()
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make(map[int]bool)
+	focused := 0
+	for _, m := range monitors {
+		visible[m.ActiveWorkspace.ID] = true
+		if m.SpecialWorkspace.ID != 0 {
+			visible[m.SpecialWorkspace.ID] = true
+		}
+		if m.Focused {
+			focused = m.ActiveWorkspace.ID
+		}
+	}
+
+	result := make([]*window.WorkspaceInfo, 0, len(workspaces))
+	for i := range workspaces {
+		info := workspaces[i].toWorkspaceInfo()
+		info.Visible = visible[info.ID]
+		info.Active = info.ID == focused
+		result = append(result, info)
+	}
+	return result, nil
+}
+
+// ActiveWorkspace returns the focused Hyprland workspace
+func (h *HyprlandProvider) ActiveWorkspace() (*window.WorkspaceInfo, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return nil, err
+	}
+
+	workspace, err := client.activeWorkspace()
+	if err != nil {
+		return nil, err
+	}
+
+	info := workspace.toWorkspaceInfo()
+	info.Visible = true
+	info.Active = true
+	return info, nil
+}
+
+// ListMonitors returns every monitor Hyprland drives
+func (h *HyprlandProvider) ListMonitors() ([]*window.MonitorInfo, error) {
+	client, err := newHyprlandClient()
+	if err != nil {
+		return nil, err
+	}
+
+	monitors, err := client.monitors()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*window.MonitorInfo, 0, len(monitors))
+	for _, m := range monitors {
+		result = append(result, &window.MonitorInfo{
+			ID:              m.ID,
+			Name:            m.Name,
+			Description:     m.Description,
+			Width:           m.Width,
+			Height:          m.Height,
+			Scale:           m.Scale,
+			X:               m.X,
+			Y:               m.Y,
+			Focused:         bool(m.Focused),
+			ActiveWorkspace: m.ActiveWorkspace.Name,
+		})
+	}
+	return result, nil
+}
+
+// monitorNames maps monitor IDs to connector names. Failures yield an empty
+// map so callers fall back to the numeric ID.
+func (c *hyprlandClient) monitorNames() map[int]string {
+	monitors, err := c.monitors()
+	if err != nil {
+		return nil
+	}
+
+	names := make(map[int]string, len(monitors))
+	for _, m := range monitors {
+		names[m.ID] = m.Name
+	}
+	return names
+}
+
+// toWorkspaceInfo converts Hyprland's workspace JSON into the common workspace structure
+func (w *hyprlandWorkspace) toWorkspaceInfo() *window.WorkspaceInfo {
+	return &window.WorkspaceInfo{
+		ID:      w.ID,
+		Name:    w.Name,
+		Monitor: w.Monitor,
+		Windows: w.Windows,
+		// Special (scratchpad) workspaces have negative IDs and a special: prefix
+		Special: w.ID < 0 || strings.HasPrefix(w.Name, "special"),
+	}
+}
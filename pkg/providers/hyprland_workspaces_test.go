@@ -0,0 +1,66 @@
+package providers
+
+import "testing"
+
+func TestHyprlandProvider_Workspaces(t *testing.T) {
+	newFakeHyprland(t, map[string]string{
+		"j/workspaces": `[
+			{"id":1,"name":"1","monitor":"DP-1","monitorID":0,"windows":3},
+			{"id":2,"name":"2","monitor":"HDMI-A-1","monitorID":1,"windows":0},
+			{"id":4,"name":"4","monitor":"DP-1","monitorID":0,"windows":1},
+			{"id":-98,"name":"special:scratch","monitor":"DP-1","monitorID":0,"windows":1}
+		]`,
+		"j/monitors": `[
+			{"id":0,"name":"DP-1","width":2560,"height":1440,"x":0,"y":0,"scale":1.0,"focused":false,"activeWorkspace":{"id":1,"name":"1"},"specialWorkspace":{"id":0,"name":""}},
+			{"id":1,"name":"HDMI-A-1","width":1920,"height":1080,"x":2560,"y":0,"scale":1.5,"focused":true,"activeWorkspace":{"id":2,"name":"2"},"specialWorkspace":{"id":0,"name":""}}
+		]`,
+		"j/activeworkspace": `{"id":2,"name":"2","monitor":"HDMI-A-1","monitorID":1,"windows":0}`,
+	})
+
+	provider := &HyprlandProvider{}
+
+	workspaces, err := provider.ListWorkspaces()
+	if err != nil {
+		t.Fatalf("ListWorkspaces() error: %v", err)
+	}
+	if len(workspaces) != 4 {
+		t.Fatalf("expected 4 workspaces, got %d", len(workspaces))
+	}
+
+	expected := []struct {
+		visible, active, special bool
+	}{
+		{true, false, false},
+		{true, true, false},
+		{false, false, false},
+		{false, false, true},
+	}
+	for i, want := range expected {
+		ws := workspaces[i]
+		if ws.Visible != want.visible || ws.Active != want.active || ws.Special != want.special {
+			t.Errorf("workspace %s: visible=%v active=%v special=%v, want %+v", ws.Name, ws.Visible, ws.Active, ws.Special, want)
+		}
+	}
+	if workspaces[0].Windows != 3 || workspaces[0].Monitor != "DP-1" {
+		t.Errorf("unexpected workspace 1: %+v", workspaces[0])
+	}
+
+	active, err := provider.ActiveWorkspace()
+	if err != nil {
+		t.Fatalf("ActiveWorkspace() error: %v", err)
+	}
+	if active.ID != 2 || active.Monitor != "HDMI-A-1" || !active.Active {
+		t.Errorf("unexpected active workspace: %+v", active)
+	}
+
+	monitors, err := provider.ListMonitors()
+	if err != nil {
+		t.Fatalf("ListMonitors() error: %v", err)
+	}
+	if len(monitors) != 2 || monitors[1].Scale != 1.5 || monitors[1].X != 2560 || !monitors[1].Focused {
+		t.Errorf("unexpected monitors: %+v %+v", monitors[0], monitors[1])
+	}
+	if monitors[1].ActiveWorkspace != "2" {
+		t.Errorf("monitor active workspace = %q, want %q", monitors[1].ActiveWorkspace, "2")
+	}
+}
@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// I3Provider implements window information retrieval for i3 on X11. i3 speaks
+// the same i3-ipc protocol as Sway, so everything but the socket lookup is shared.
+type I3Provider struct {
+	SwayProvider
+}
+
+// NewI3Provider creates a provider talking to the running i3 instance
+func NewI3Provider() *I3Provider {
+	return &I3Provider{SwayProvider{socketPath: i3SocketPath}}
+}
+
+// Name returns the provider name
+func (i *I3Provider) Name() string {
+	return "i3"
+}
+
+// i3SocketPath finds i3's IPC socket from $I3SOCK, falling back to asking i3
+// itself, which reads the I3_SOCKET_PATH property of the X11 root window
+func i3SocketPath() (string, error) {
+	if socketPath := os.Getenv("I3SOCK"); socketPath != "" {
+		return socketPath, nil
+	}
+
+	output, err := exec.Command("i3", "--get-socketpath").Output()
+	if err != nil {
+		return "", fmt.Errorf("I3SOCK not set and 'i3 --get-socketpath' failed - are we running under i3? (%w)", err)
+	}
+
+	socketPath := strings.TrimSpace(string(output))
+	if socketPath == "" {
+		return "", fmt.Errorf("i3 did not report an IPC socket path")
+	}
+	return socketPath, nil
+}
@@ -0,0 +1,53 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestI3Provider_GetActiveWindow(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3GetTree: i3Reply(`{"id": 1, "type": "root", "nodes": [
+			{"id": 2, "type": "output", "name": "DP-2", "nodes": [
+				{"id": 3, "type": "workspace", "name": "4", "nodes": [
+					{"id": 94557, "type": "con", "name": "htop", "focused": true, "window": 12582919,
+					 "window_properties": {"class": "XTerm", "instance": "xterm", "title": "htop"}}
+				]}
+			]}
+		]}`),
+	})
+	t.Setenv("I3SOCK", fake.socketPath)
+
+	provider := NewI3Provider()
+	info, err := provider.GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Class != "XTerm" || info.Instance != "xterm" || info.Workspace != "4" || info.Monitor != "DP-2" {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestI3Provider_GetActiveWindowNoFocus(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3GetTree: i3Reply(`{"id": 1, "type": "root", "nodes": []}`),
+	})
+	t.Setenv("I3SOCK", fake.socketPath)
+
+	_, err := NewI3Provider().GetActiveWindow()
+	if err == nil || strings.Contains(err.Error(), "Sway") {
+		t.Errorf("GetActiveWindow() error = %v, want one that doesn't blame Sway", err)
+	}
+}
+
+func TestI3Provider_CompositorVersion(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3GetVersion: i3Reply(`{"major":4,"minor":23,"patch":0,"human_readable":"4.23 (2023-10-29)"}`),
+	})
+	t.Setenv("I3SOCK", fake.socketPath)
+
+	version, err := NewI3Provider().CompositorVersion()
+	if err != nil || version != "4.23 (2023-10-29)" {
+		t.Errorf("CompositorVersion() = %q, %v", version, err)
+	}
+}
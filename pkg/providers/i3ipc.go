@@ -0,0 +1,275 @@
+package providers
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// i3ipcMagic starts every i3-ipc message, in both directions
+const i3ipcMagic = "i3-ipc"
+
+// i3ipcHeaderSize is the magic string plus payload length and message type
+const i3ipcHeaderSize = len(i3ipcMagic) + 8
+
+// i3ipcEventBit is set in the type of messages that are events, not replies
+const i3ipcEventBit = 1 << 31
+
+// i3MessageType identifies an i3-ipc request and its reply
+type i3MessageType uint32
+
+// i3-ipc message types shared by i3 and Sway, plus Sway's own additions
+const (
+	i3RunCommand      i3MessageType = 0
+	i3GetWorkspaces   i3MessageType = 1
+	i3Subscribe       i3MessageType = 2
+	i3GetOutputs      i3MessageType = 3
+	i3GetTree         i3MessageType = 4
+	i3GetMarks        i3MessageType = 5
+	i3GetBarConfig    i3MessageType = 6
+	i3GetVersion      i3MessageType = 7
+	i3GetBindingModes i3MessageType = 8
+	i3GetConfig       i3MessageType = 9
+	i3SendTick        i3MessageType = 10
+	i3Sync            i3MessageType = 11
+	i3GetBindingState i3MessageType = 12
+	swayGetInputs     i3MessageType = 100
+	swayGetSeats      i3MessageType = 101
+)
+
+// i3Client speaks the i3-ipc protocol over a single connection. Requests are
+// serialized, so a client can be shared between goroutines.
+type i3Client struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// i3CommandResult is the outcome of one command sent with RUN_COMMAND
+type i3CommandResult struct {
+	Success    bool   `json:"success"`
+	ParseError bool   `json:"parse_error"`
+	Error      string `json:"error"`
+}
+
+// i3Workspace represents the JSON structure returned by GET_WORKSPACES
+type i3Workspace struct {
+	ID      int      `json:"id"`
+	Num     int      `json:"num"`
+	Name    string   `json:"name"`
+	Visible flexBool `json:"visible"`
+	Focused flexBool `json:"focused"`
+	Urgent  flexBool `json:"urgent"`
+	Rect    swayRect `json:"rect"`
+	Output  string   `json:"output"`
+}
+
+// i3OutputMode is a resolution and refresh rate supported by an output
+type i3OutputMode struct {
+	Width   int `json:"width"`
+	Height  int `json:"height"`
+	Refresh int `json:"refresh"`
+}
+
+// i3Output represents the JSON structure returned by GET_OUTPUTS
+type i3Output struct {
+	Name             string         `json:"name"`
+	Make             string         `json:"make"`
+	Model            string         `json:"model"`
+	Serial           string         `json:"serial"`
+	Active           flexBool       `json:"active"`
+	DPMS             flexBool       `json:"dpms"`
+	Power            flexBool       `json:"power"`
+	Primary          flexBool       `json:"primary"`
+	Focused          flexBool       `json:"focused"`
+	Scale            float64        `json:"scale"`
+	SubpixelHinting  string         `json:"subpixel_hinting"`
+	Transform        string         `json:"transform"`
+	CurrentWorkspace *string        `json:"current_workspace"`
+	Modes            []i3OutputMode `json:"modes"`
+	CurrentMode      *i3OutputMode  `json:"current_mode"`
+	Rect             swayRect       `json:"rect"`
+}
+
+// i3Version represents the JSON structure returned by GET_VERSION
+type i3Version struct {
+	Major                int    `json:"major"`
+	Minor                int    `json:"minor"`
+	Patch                int    `json:"patch"`
+	HumanReadable        string `json:"human_readable"`
+	LoadedConfigFileName string `json:"loaded_config_file_name"`
+}
+
+// swayInput represents the JSON structure returned by Sway's GET_INPUTS
+type swayInput struct {
+	Identifier           string   `json:"identifier"`
+	Name                 string   `json:"name"`
+	Vendor               int      `json:"vendor"`
+	Product              int      `json:"product"`
+	Type                 string   `json:"type"`
+	XKBActiveLayoutName  *string  `json:"xkb_active_layout_name"`
+	XKBLayoutNames       []string `json:"xkb_layout_names"`
+	XKBActiveLayoutIndex *int     `json:"xkb_active_layout_index"`
+}
+
+// newI3Client connects to the i3-ipc socket at socketPath
+func newI3Client(socketPath string) (*i3Client, error) {
+	conn, err := net.Dial("unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to i3-ipc socket: %w", err)
+	}
+	return &i3Client{conn: conn}, nil
+}
+
+// Close closes the underlying connection
+func (c *i3Client) Close() error {
+	return c.conn.Close()
+}
+
+// writeMessage frames and sends a single message
+func (c *i3Client) writeMessage(msgType i3MessageType, payload []byte) error {
+	message := make([]byte, i3ipcHeaderSize, i3ipcHeaderSize+len(payload))
+	copy(message, i3ipcMagic)
+	binary.NativeEndian.PutUint32(message[6:], uint32(len(payload)))
+	binary.NativeEndian.PutUint32(message[10:], uint32(msgType))
+	message = append(message, payload...)
+
+	if _, err := c.conn.Write(message); err != nil {
+		return fmt.Errorf("failed to send i3-ipc request: %w", err)
+	}
+	return nil
+}
+
+// readMessage reads one complete message, however large the payload is
+func (c *i3Client) readMessage() (uint32, []byte, error) {
+	header := make([]byte, i3ipcHeaderSize)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return 0, nil, fmt.Errorf("failed to read i3-ipc header: %w", err)
+	}
+
+	if string(header[:len(i3ipcMagic)]) != i3ipcMagic {
+		return 0, nil, fmt.Errorf("invalid i3-ipc magic %q", header[:len(i3ipcMagic)])
+	}
+
+	length := binary.NativeEndian.Uint32(header[6:])
+	msgType := binary.NativeEndian.Uint32(header[10:])
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.conn, payload); err != nil {
+		return 0, nil, fmt.Errorf("failed to read i3-ipc payload: %w", err)
+	}
+	return msgType, payload, nil
+}
+
+// request sends a message and returns the payload of its reply
+func (c *i3Client) request(msgType i3MessageType, payload []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.writeMessage(msgType, payload); err != nil {
+		return nil, err
+	}
+
+	for {
+		replyType, reply, err := c.readMessage()
+		if err != nil {
+			return nil, err
+		}
+		// Events can arrive interleaved with replies on subscribed connections
+		if replyType&i3ipcEventBit != 0 {
+			continue
+		}
+		if i3MessageType(replyType) != msgType {
+			return nil, fmt.Errorf("unexpected i3-ipc reply type %d for request %d", replyType, msgType)
+		}
+		return reply, nil
+	}
+}
+
+// requestJSON sends a message and decodes the reply into v
+func (c *i3Client) requestJSON(msgType i3MessageType, payload []byte, v any) error {
+	reply, err := c.request(msgType, payload)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(reply, v); err != nil {
+		return fmt.Errorf("failed to decode i3-ipc reply to request %d: %w", msgType, err)
+	}
+	return nil
+}
+
+// runCommand executes commands (separated by ; or ,) and returns one result per command
+func (c *i3Client) runCommand(command string) ([]i3CommandResult, error) {
+	var results []i3CommandResult
+	if err := c.requestJSON(i3RunCommand, []byte(command), &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// workspaces returns all workspaces
+func (c *i3Client) workspaces() ([]i3Workspace, error) {
+	var workspaces []i3Workspace
+	if err := c.requestJSON(i3GetWorkspaces, nil, &workspaces); err != nil {
+		return nil, err
+	}
+	return workspaces, nil
+}
+
+// outputs returns all outputs, including disabled ones
+func (c *i3Client) outputs() ([]i3Output, error) {
+	var outputs []i3Output
+	if err := c.requestJSON(i3GetOutputs, nil, &outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// tree returns the full layout tree
+func (c *i3Client) tree() (*swayNode, error) {
+	var root swayNode
+	if err := c.requestJSON(i3GetTree, nil, &root); err != nil {
+		return nil, err
+	}
+	return &root, nil
+}
+
+// marks returns the names of all marks currently set
+func (c *i3Client) marks() ([]string, error) {
+	var marks []string
+	if err := c.requestJSON(i3GetMarks, nil, &marks); err != nil {
+		return nil, err
+	}
+	return marks, nil
+}
+
+// version returns version information about the window manager
+func (c *i3Client) version() (*i3Version, error) {
+	var v i3Version
+	if err := c.requestJSON(i3GetVersion, nil, &v); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// bindingState returns the name of the active binding mode
+func (c *i3Client) bindingState() (string, error) {
+	var state struct {
+		Name string `json:"name"`
+	}
+	if err := c.requestJSON(i3GetBindingState, nil, &state); err != nil {
+		return "", err
+	}
+	return state.Name, nil
+}
+
+// inputs returns Sway's input devices
+func (c *i3Client) inputs() ([]swayInput, error) {
+	var inputs []swayInput
+	if err := c.requestJSON(swayGetInputs, nil, &inputs); err != nil {
+		return nil, err
+	}
+	return inputs, nil
+}
@@ -0,0 +1,241 @@
+package providers
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeI3 is a minimal i3-ipc server answering requests with canned replies
+type fakeI3 struct {
+	socketPath string
+	replies    map[i3MessageType]func(payload string) string
+	requests   chan string
+	// subscribed receives connections once they have subscribed to events
+	subscribed chan net.Conn
+}
+
+func newFakeI3(t *testing.T, replies map[i3MessageType]func(payload string) string) *fakeI3 {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "ipc.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	f := &fakeI3{
+		socketPath: socketPath,
+		replies:    replies,
+		requests:   make(chan string, 32),
+		subscribed: make(chan net.Conn, 1),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeI3) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		header := make([]byte, i3ipcHeaderSize)
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		payload := make([]byte, binary.NativeEndian.Uint32(header[6:]))
+		if _, err := io.ReadFull(conn, payload); err != nil {
+			return
+		}
+		msgType := i3MessageType(binary.NativeEndian.Uint32(header[10:]))
+
+		select {
+		case f.requests <- string(payload):
+		default:
+		}
+
+		reply := "null"
+		if handler, ok := f.replies[msgType]; ok {
+			reply = handler(string(payload))
+		}
+		writeI3Message(conn, uint32(msgType), reply)
+
+		if msgType == i3Subscribe {
+			f.subscribed <- conn
+		}
+	}
+}
+
+// writeI3Message frames a message the way i3 and Sway do
+func writeI3Message(w io.Writer, msgType uint32, payload string) {
+	header := make([]byte, i3ipcHeaderSize)
+	copy(header, i3ipcMagic)
+	binary.NativeEndian.PutUint32(header[6:], uint32(len(payload)))
+	binary.NativeEndian.PutUint32(header[10:], msgType)
+	w.Write(append(header, payload...))
+}
+
+// i3Reply returns a handler that always answers with body
+func i3Reply(body string) func(string) string {
+	return func(string) string { return body }
+}
+
+func TestI3Client_TypedRequests(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3RunCommand:      i3Reply(`[{"success":true},{"success":false,"parse_error":true,"error":"Unknown command"}]`),
+		i3GetWorkspaces:   i3Reply(`[{"id":4,"num":1,"name":"1","visible":true,"focused":true,"output":"eDP-1"}]`),
+		i3GetOutputs:      i3Reply(`[{"name":"eDP-1","active":true,"scale":2,"current_workspace":"1","rect":{"x":0,"y":0,"width":1280,"height":800}}]`),
+		i3GetTree:         i3Reply(`{"id":1,"type":"root","nodes":[{"id":2,"type":"output"}]}`),
+		i3GetMarks:        i3Reply(`["a","b"]`),
+		i3GetVersion:      i3Reply(`{"major":1,"minor":9,"patch":0,"human_readable":"1.9"}`),
+		i3GetBindingState: i3Reply(`{"name":"resize"}`),
+		swayGetInputs:     i3Reply(`[{"identifier":"1:1:AT_Translated_Set_2_keyboard","type":"keyboard","xkb_active_layout_name":"English (US)"}]`),
+	})
+
+	client, err := newI3Client(fake.socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	results, err := client.runCommand("focus; bogus")
+	if err != nil || len(results) != 2 || !results[0].Success || results[1].Error != "Unknown command" {
+		t.Errorf("runCommand() = %+v, %v", results, err)
+	}
+	if got := <-fake.requests; got != "focus; bogus" {
+		t.Errorf("RUN_COMMAND payload = %q", got)
+	}
+
+	workspaces, err := client.workspaces()
+	if err != nil || len(workspaces) != 1 || workspaces[0].Output != "eDP-1" || !workspaces[0].Focused {
+		t.Errorf("workspaces() = %+v, %v", workspaces, err)
+	}
+
+	outputs, err := client.outputs()
+	if err != nil || len(outputs) != 1 || outputs[0].Scale != 2 || *outputs[0].CurrentWorkspace != "1" {
+		t.Errorf("outputs() = %+v, %v", outputs, err)
+	}
+
+	tree, err := client.tree()
+	if err != nil || tree.Type != "root" || len(tree.Nodes) != 1 {
+		t.Errorf("tree() = %+v, %v", tree, err)
+	}
+
+	marks, err := client.marks()
+	if err != nil || strings.Join(marks, ",") != "a,b" {
+		t.Errorf("marks() = %v, %v", marks, err)
+	}
+
+	version, err := client.version()
+	if err != nil || version.Minor != 9 {
+		t.Errorf("version() = %+v, %v", version, err)
+	}
+
+	mode, err := client.bindingState()
+	if err != nil || mode != "resize" {
+		t.Errorf("bindingState() = %q, %v", mode, err)
+	}
+
+	inputs, err := client.inputs()
+	if err != nil || len(inputs) != 1 || *inputs[0].XKBActiveLayoutName != "English (US)" {
+		t.Errorf("inputs() = %+v, %v", inputs, err)
+	}
+}
+
+func TestI3Client_LargePayload(t *testing.T) {
+	// Large trees arrive in several chunks; a single Read would truncate them
+	title := strings.Repeat("x", 1<<20)
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3GetTree: i3Reply(`{"id":1,"type":"root","name":"` + title + `"}`),
+	})
+
+	client, err := newI3Client(fake.socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	tree, err := client.tree()
+	if err != nil {
+		t.Fatalf("tree() error: %v", err)
+	}
+	if tree.Name == nil || len(*tree.Name) != len(title) {
+		t.Errorf("tree name was truncated")
+	}
+}
+
+func TestI3Client_ProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		respond func(conn net.Conn)
+		errText string
+	}{
+		{
+			name: "bad magic",
+			respond: func(conn net.Conn) {
+				conn.Write([]byte("i4-ipc\x00\x00\x00\x00\x04\x00\x00\x00"))
+			},
+			errText: "magic",
+		},
+		{
+			name: "wrong reply type",
+			respond: func(conn net.Conn) {
+				writeI3Message(conn, uint32(i3GetWorkspaces), "[]")
+			},
+			errText: "unexpected i3-ipc reply type",
+		},
+		{
+			name: "truncated payload",
+			respond: func(conn net.Conn) {
+				header := make([]byte, i3ipcHeaderSize)
+				copy(header, i3ipcMagic)
+				binary.NativeEndian.PutUint32(header[6:], 100)
+				binary.NativeEndian.PutUint32(header[10:], uint32(i3GetTree))
+				conn.Write(append(header, "{}"...))
+			},
+			errText: "payload",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			socketPath := filepath.Join(t.TempDir(), "ipc.sock")
+			listener, err := net.Listen("unix", socketPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				io.ReadFull(conn, make([]byte, i3ipcHeaderSize))
+				tt.respond(conn)
+			}()
+
+			client, err := newI3Client(socketPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			_, err = client.tree()
+			if err == nil || !strings.Contains(err.Error(), tt.errText) {
+				t.Errorf("tree() error = %v, want it to mention %q", err, tt.errText)
+			}
+		})
+	}
+}
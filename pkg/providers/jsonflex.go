@@ -0,0 +1,65 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// Compositors change the JSON type of a field between releases every now and
+// then (Hyprland's fullscreen went from a bool to a mode number). The types
+// below accept every shape we've seen so a single changed field doesn't fail
+// the whole decode.
+
+// flexBool decodes a JSON bool, a number (non-zero is true) or null (false)
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "null", "false":
+		*b = false
+		return nil
+	case "true":
+		*b = true
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("cannot decode %s as a bool", data)
+	}
+	*b = n != 0
+	return nil
+}
+
+// flexInt decodes a JSON number, a numeric string, a bool (true is 1) or null (0)
+type flexInt int
+
+func (i *flexInt) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "null", "false":
+		*i = 0
+		return nil
+	case "true":
+		*i = 1
+		return nil
+	}
+
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+
+	n, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("cannot decode %s as an integer", data)
+	}
+	*i = flexInt(n)
+	return nil
+}
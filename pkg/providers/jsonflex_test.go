@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexBool(t *testing.T) {
+	tests := map[string]bool{
+		`true`:  true,
+		`false`: false,
+		`null`:  false,
+		`0`:     false,
+		`2`:     true,
+		`1.0`:   true,
+	}
+	for input, want := range tests {
+		var got flexBool
+		if err := json.Unmarshal([]byte(input), &got); err != nil || bool(got) != want {
+			t.Errorf("flexBool(%s) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	var b flexBool
+	if err := json.Unmarshal([]byte(`"yes"`), &b); err == nil {
+		t.Error("expected an error for a string")
+	}
+}
+
+func TestFlexInt(t *testing.T) {
+	tests := map[string]int{
+		`42`:    42,
+		`"42"`:  42,
+		`true`:  1,
+		`false`: 0,
+		`null`:  0,
+	}
+	for input, want := range tests {
+		var got flexInt
+		if err := json.Unmarshal([]byte(input), &got); err != nil || int(got) != want {
+			t.Errorf("flexInt(%s) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+
+	var i flexInt
+	if err := json.Unmarshal([]byte(`"abc"`), &i); err == nil {
+		t.Error("expected an error for a non-numeric string")
+	}
+}
+
+func TestFlexFieldsAcrossProviders(t *testing.T) {
+	// Flags sent as numbers instead of bools still decode
+	var monitor hyprlandMonitor
+	if err := json.Unmarshal([]byte(`{"name":"DP-1","focused":1,"dpmsStatus":1,"vrr":0,"disabled":null}`), &monitor); err != nil {
+		t.Fatalf("failed to decode Hyprland monitor: %v", err)
+	}
+	if !monitor.Focused || !monitor.DPMSStatus || monitor.VRR || monitor.Disabled {
+		t.Errorf("Hyprland monitor = %+v", monitor)
+	}
+
+	var workspace hyprlandWorkspace
+	if err := json.Unmarshal([]byte(`{"id":1,"hasfullscreen":1}`), &workspace); err != nil || !workspace.HasFullscreen {
+		t.Errorf("Hyprland workspace = %+v, %v", workspace, err)
+	}
+
+	var node swayNode
+	if err := json.Unmarshal([]byte(`{"id":4,"focused":1,"urgent":0,"sticky":null,"inhibit_idle":1}`), &node); err != nil {
+		t.Fatalf("failed to decode Sway node: %v", err)
+	}
+	if !node.Focused || node.Urgent || node.Sticky || node.InhibitIdle == nil || !*node.InhibitIdle {
+		t.Errorf("Sway node = %+v", node)
+	}
+
+	var i3Workspaces []i3Workspace
+	if err := json.Unmarshal([]byte(`[{"name":"1","focused":1,"visible":1,"urgent":0}]`), &i3Workspaces); err != nil || !i3Workspaces[0].Focused {
+		t.Errorf("i3 workspaces = %+v, %v", i3Workspaces, err)
+	}
+}
@@ -0,0 +1,267 @@
+package providers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// KDEProvider implements window information retrieval for KDE Plasma. KWin
+// only shares window details with its own scripts, so yawi loads a short
+// script that reports back over D-Bus.
+type KDEProvider struct {
+	// bus opens the connection to KWin; nil means the D-Bus session bus
+	bus func() (kdeBus, error)
+}
+
+// Name returns the provider name
+func (k *KDEProvider) Name() string {
+	return "KDE Plasma"
+}
+
+// kdeScriptTimeout is how long to wait for a KWin script to report back
+const kdeScriptTimeout = 2 * time.Second
+
+// kdeScriptCounter keeps plugin names unique within the process
+var kdeScriptCounter atomic.Uint64
+
+// kwinActiveWindowScript reports the active window. It runs in KWin's
+// JavaScript engine, which names things differently on Plasma 5 and 6.
+const kwinActiveWindowScript = `(function () {
+    const w = workspace.activeWindow || workspace.activeClient;
+    let info = null;
+    if (w) {
+        const geometry = w.frameGeometry || w.geometry;
+        info = {
+            internalId: String(w.internalId),
+            caption: w.caption,
+            resourceClass: String(w.resourceClass),
+            resourceName: String(w.resourceName),
+            pid: w.pid,
+            desktops: (w.desktops || []).map(d => ({id: d.id, name: d.name})),
+            desktop: typeof w.desktop === "number" ? w.desktop : null,
+            onAllDesktops: w.onAllDesktops,
+            output: w.output ? w.output.name : null,
+            screen: typeof w.screen === "number" ? w.screen : null,
+            geometry: {x: geometry.x, y: geometry.y, width: geometry.width, height: geometry.height},
+            fullScreen: w.fullScreen,
+            keepAbove: w.keepAbove,
+            activities: (w.activities || []).map(String)
+        };
+    }
+    callDBus(%s, %s, %s, "Report", JSON.stringify(info));
+})();
+`
+
+// kwinWindow is what kwinActiveWindowScript reports
+type kwinWindow struct {
+	InternalID    string `json:"internalId"`
+	Caption       string `json:"caption"`
+	ResourceClass string `json:"resourceClass"`
+	ResourceName  string `json:"resourceName"`
+	PID           int    `json:"pid"`
+	// Desktops is set on Plasma 6, Desktop (1-based) on Plasma 5
+	Desktops []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"desktops"`
+	Desktop       *int     `json:"desktop"`
+	OnAllDesktops flexBool `json:"onAllDesktops"`
+	// Output is set on Plasma 6, Screen on Plasma 5
+	Output     *string  `json:"output"`
+	Screen     *int     `json:"screen"`
+	Geometry   swayRect `json:"geometry"`
+	FullScreen flexBool `json:"fullScreen"`
+	KeepAbove  flexBool `json:"keepAbove"`
+	// Activities is empty for windows shown on every Activity
+	Activities []string `json:"activities"`
+}
+
+// kdeCallback is exported on the bus for KWin scripts to report back to
+type kdeCallback struct {
+	reports chan string
+}
+
+// Report receives a script's JSON result
+func (c *kdeCallback) Report(payload string) *dbus.Error {
+	select {
+	case c.reports <- payload:
+	default:
+	}
+	return nil
+}
+
+// GetActiveWindow retrieves the currently active window from KWin, along
+// with the virtual desktop and Activity it is shown on
+func (k *KDEProvider) GetActiveWindow() (*window.WindowInfo, error) {
+	bus, err := k.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	details, desktops := kdeContext(bus)
+	payload, err := runKWinScriptFile(bus, kwinActiveWindowScript)
+	if err != nil {
+		return nil, err
+	}
+	if payload == "null" {
+		return nil, fmt.Errorf("no active window found in KWin")
+	}
+
+	var w kwinWindow
+	if err := json.Unmarshal([]byte(payload), &w); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal KWin window: %w", err)
+	}
+
+	info := w.toWindowInfo()
+	// Report the same desktop as the workspace and in the details; windows on
+	// every desktop are shown on the current one
+	if desktop, workspace, ok := w.shownDesktop(desktops, details.DesktopID); ok && len(desktops) > 0 {
+		info.Workspace = workspace
+		details.DesktopID, details.DesktopName = desktop.ID, desktop.Name
+	}
+	details.Activities = w.Activities
+	if details.DesktopID != "" || details.ActivityID != "" || len(details.Activities) > 0 {
+		info.KDE = details
+	}
+	return info, nil
+}
+
+// runKWinScriptFile loads script (a format string receiving the callback's
+// service, path and interface) into KWin, runs it and returns what it reported
+func runKWinScriptFile(bus kdeBus, script string) (string, error) {
+	callback := &kdeCallback{reports: make(chan string, 1)}
+	if err := bus.export(callback, kdeCallbackPath, kdeCallbackIface); err != nil {
+		return "", fmt.Errorf("failed to export KWin callback: %w", err)
+	}
+
+	file, err := os.CreateTemp("", "yawi-kwin-*.js")
+	if err != nil {
+		return "", fmt.Errorf("failed to create KWin script: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	source := fmt.Sprintf(script, strconv.Quote(bus.uniqueName()), strconv.Quote(string(kdeCallbackPath)), strconv.Quote(kdeCallbackIface))
+	if _, err := file.WriteString(source); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write KWin script: %w", err)
+	}
+	file.Close()
+
+	plugin := fmt.Sprintf("yawi-%d-%d", os.Getpid(), kdeScriptCounter.Add(1))
+	var id int32
+	if err := bus.call(kwinName, kwinScriptingPath, kwinScriptingIface+".loadScript", []any{file.Name(), plugin}, &id); err != nil {
+		return "", fmt.Errorf("failed to load KWin script: %w", err)
+	}
+	if id < 0 {
+		return "", fmt.Errorf("KWin refused to load the script")
+	}
+	defer bus.call(kwinName, kwinScriptingPath, kwinScriptingIface+".unloadScript", []any{plugin})
+
+	if err := runKWinScript(bus, id); err != nil {
+		return "", err
+	}
+
+	select {
+	case payload := <-callback.reports:
+		return payload, nil
+	case <-time.After(kdeScriptTimeout):
+		return "", fmt.Errorf("KWin script did not report back within %s", kdeScriptTimeout)
+	}
+}
+
+// runKWinScript starts a loaded script. Plasma 6 moved script objects under
+// /Scripting; Plasma 5 keeps them at the root.
+func runKWinScript(bus kdeBus, id int32) error {
+	var failures []string
+	for _, path := range []string{fmt.Sprintf("/Scripting/Script%d", id), fmt.Sprintf("/%d", id)} {
+		err := bus.call(kwinName, dbus.ObjectPath(path), kwinScriptIface+".run", nil)
+		if err == nil {
+			return nil
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", path, err))
+	}
+	return fmt.Errorf("failed to run KWin script: %s", strings.Join(failures, "; "))
+}
+
+// toWindowInfo converts KWin's window into the common window structure
+func (w *kwinWindow) toWindowInfo() *window.WindowInfo {
+	info := &window.WindowInfo{
+		ID:         strings.Trim(w.InternalID, "{}"),
+		Title:      w.Caption,
+		Class:      w.ResourceClass,
+		Instance:   w.ResourceName,
+		PID:        w.PID,
+		Fullscreen: window.Bool(bool(w.FullScreen)),
+		Pinned:     window.Bool(bool(w.OnAllDesktops)),
+	}
+
+	_, info.Workspace, _ = w.shownDesktop(nil, "")
+
+	switch {
+	case w.Output != nil:
+		info.Monitor = *w.Output
+	case w.Screen != nil:
+		info.Monitor = strconv.Itoa(*w.Screen)
+	}
+
+	if w.Geometry.Width > 0 && w.Geometry.Height > 0 {
+		info.Geometry = &window.Geometry{X: w.Geometry.X, Y: w.Geometry.Y, Width: w.Geometry.Width, Height: w.Geometry.Height}
+	}
+	return info
+}
+
+// shownDesktop picks the virtual desktop to report for a window on one or more
+// desktops: current if the window is on it, otherwise its first. workspace is
+// the desktop's name, or its number when it is unnamed or, on Plasma 5, not
+// found in desktops. ok is false for windows on every desktop.
+func (w *kwinWindow) shownDesktop(desktops []kwinDesktop, current string) (desktop kwinDesktop, workspace string, ok bool) {
+	switch {
+	case len(w.Desktops) > 0:
+		chosen := w.Desktops[0]
+		for _, d := range w.Desktops {
+			if d.ID == current {
+				chosen = d
+				break
+			}
+		}
+		desktop = kwinDesktop{ID: chosen.ID, Name: chosen.Name}
+		workspace = chosen.Name
+		for _, d := range desktops {
+			if d.ID == chosen.ID && workspace == "" {
+				// Unnamed desktops go by their number, as in ListWorkspaces
+				workspace = strconv.Itoa(int(d.Position) + 1)
+			}
+		}
+		return desktop, workspace, true
+	case w.Desktop != nil && *w.Desktop > 0:
+		workspace = strconv.Itoa(*w.Desktop)
+		for _, d := range desktops {
+			if int(d.Position) == *w.Desktop-1 {
+				desktop = d
+				if d.Name != "" {
+					workspace = d.Name
+				}
+				break
+			}
+		}
+		return desktop, workspace, true
+	}
+	return kwinDesktop{}, "", false
+}
+
+// connect opens the bus used to talk to KWin
+func (k *KDEProvider) connect() (kdeBus, error) {
+	if k.bus != nil {
+		return k.bus()
+	}
+	return newKDESessionBus()
+}
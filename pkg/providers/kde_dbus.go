@@ -0,0 +1,75 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// KWin's bus name and scripting interface
+const (
+	kwinName           = "org.kde.KWin"
+	kwinScriptingPath  = dbus.ObjectPath("/Scripting")
+	kwinScriptingIface = "org.kde.kwin.Scripting"
+	kwinScriptIface    = "org.kde.kwin.Script"
+	kdeCallbackPath    = dbus.ObjectPath("/org/alde/yawi/KWin")
+	kdeCallbackIface   = "org.alde.yawi.KWin"
+)
+
+// Virtual desktops are exported by KWin, Activities by their own service
+const (
+	kwinDesktopsPath   = dbus.ObjectPath("/VirtualDesktopManager")
+	kwinDesktopsIface  = "org.kde.KWin.VirtualDesktopManager"
+	kdeActivityName    = "org.kde.ActivityManager"
+	kdeActivitiesPath  = dbus.ObjectPath("/ActivityManager/Activities")
+	kdeActivitiesIface = "org.kde.ActivityManager.Activities"
+)
+
+// kdeBus is the part of the session bus the KDE provider needs, so tests can
+// stand in for KWin
+type kdeBus interface {
+	// call invokes method on dest and stores the reply values in results
+	call(dest string, path dbus.ObjectPath, method string, args []any, results ...any) error
+	// property reads a property, named interface.member, of an object on dest
+	property(dest string, path dbus.ObjectPath, name string) (dbus.Variant, error)
+	// export publishes v's methods at path so KWin scripts can call back
+	export(v any, path dbus.ObjectPath, iface string) error
+	// uniqueName is the bus name KWin scripts call back to
+	uniqueName() string
+	Close() error
+}
+
+// kdeSessionBus talks to KWin over a private session bus connection
+type kdeSessionBus struct {
+	conn *dbus.Conn
+}
+
+// newKDESessionBus connects to the D-Bus session bus
+func newKDESessionBus() (*kdeSessionBus, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to D-Bus session bus: %w", err)
+	}
+	return &kdeSessionBus{conn: conn}, nil
+}
+
+func (b *kdeSessionBus) call(dest string, path dbus.ObjectPath, method string, args []any, results ...any) error {
+	return b.conn.Object(dest, path).Call(method, 0, args...).Store(results...)
+}
+
+func (b *kdeSessionBus) property(dest string, path dbus.ObjectPath, name string) (dbus.Variant, error) {
+	return b.conn.Object(dest, path).GetProperty(name)
+}
+
+func (b *kdeSessionBus) export(v any, path dbus.ObjectPath, iface string) error {
+	return b.conn.Export(v, path, iface)
+}
+
+func (b *kdeSessionBus) uniqueName() string {
+	return b.conn.Names()[0]
+}
+
+// Close closes the connection
+func (b *kdeSessionBus) Close() error {
+	return b.conn.Close()
+}
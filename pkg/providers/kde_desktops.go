@@ -0,0 +1,177 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// kwinDesktop is one entry of KWin's desktops property
+type kwinDesktop struct {
+	Position uint32
+	ID       string
+	Name     string
+}
+
+// kdeActivity is one entry of the Activity manager's ListActivitiesWithInformation reply
+type kdeActivity struct {
+	ID          string
+	Name        string
+	Description string
+	Icon        string
+	State       int32
+}
+
+// kdeActivityRunning is the Activity manager's state for a running Activity
+const kdeActivityRunning = 2
+
+// kdeDesktops returns KWin's virtual desktops and the ID of the current one
+func kdeDesktops(bus kdeBus) ([]kwinDesktop, string, error) {
+	value, err := bus.property(kwinName, kwinDesktopsPath, kwinDesktopsIface+".desktops")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read virtual desktops: %w", err)
+	}
+	var desktops []kwinDesktop
+	if err := dbus.Store([]any{value.Value()}, &desktops); err != nil {
+		return nil, "", fmt.Errorf("failed to decode virtual desktops: %w", err)
+	}
+
+	value, err = bus.property(kwinName, kwinDesktopsPath, kwinDesktopsIface+".current")
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read current virtual desktop: %w", err)
+	}
+	current, ok := value.Value().(string)
+	if !ok {
+		return nil, "", fmt.Errorf("unexpected current virtual desktop %v", value)
+	}
+	return desktops, current, nil
+}
+
+// kdeActivities returns every Activity and the ID of the current one
+func kdeActivities(bus kdeBus) ([]kdeActivity, string, error) {
+	var activities []kdeActivity
+	if err := bus.call(kdeActivityName, kdeActivitiesPath, kdeActivitiesIface+".ListActivitiesWithInformation", nil, &activities); err != nil {
+		return nil, "", fmt.Errorf("failed to list Activities: %w", err)
+	}
+	var current string
+	if err := bus.call(kdeActivityName, kdeActivitiesPath, kdeActivitiesIface+".CurrentActivity", nil, &current); err != nil {
+		return nil, "", fmt.Errorf("failed to get current Activity: %w", err)
+	}
+	return activities, current, nil
+}
+
+// kdeContext looks up the current virtual desktop and Activity, and returns
+// the desktops so the window's own can be matched. Either may be unavailable
+// (older KWin, Activities disabled), so it leaves those fields empty.
+func kdeContext(bus kdeBus) (*window.KDEDetails, []kwinDesktop) {
+	details := &window.KDEDetails{}
+	desktops, current, err := kdeDesktops(bus)
+	if err == nil {
+		details.DesktopID = current
+		for _, desktop := range desktops {
+			if desktop.ID == current {
+				details.DesktopName = desktop.Name
+			}
+		}
+	}
+	if activities, current, err := kdeActivities(bus); err == nil {
+		details.ActivityID = current
+		for _, activity := range activities {
+			if activity.ID == current {
+				details.ActivityName = activity.Name
+			}
+		}
+	}
+	return details, desktops
+}
+
+// ListWorkspaces returns KWin's virtual desktops
+func (k *KDEProvider) ListWorkspaces() ([]*window.WorkspaceInfo, error) {
+	bus, err := k.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	desktops, current, err := kdeDesktops(bus)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*window.WorkspaceInfo, 0, len(desktops))
+	for _, desktop := range desktops {
+		infos = append(infos, desktop.toWorkspaceInfo(current))
+	}
+	return infos, nil
+}
+
+// ActiveWorkspace returns the virtual desktop KWin is showing
+func (k *KDEProvider) ActiveWorkspace() (*window.WorkspaceInfo, error) {
+	workspaces, err := k.ListWorkspaces()
+	if err != nil {
+		return nil, err
+	}
+	for _, ws := range workspaces {
+		if ws.Active {
+			return ws, nil
+		}
+	}
+	return nil, fmt.Errorf("no active virtual desktop found in KWin")
+}
+
+// toWorkspaceInfo numbers desktops from 1 as Plasma's pager does. KWin
+// doesn't count windows per desktop over D-Bus, so Windows stays 0.
+func (d *kwinDesktop) toWorkspaceInfo(current string) *window.WorkspaceInfo {
+	name := d.Name
+	if name == "" {
+		name = strconv.Itoa(int(d.Position) + 1)
+	}
+	return &window.WorkspaceInfo{
+		ID:      int(d.Position) + 1,
+		Name:    name,
+		Visible: d.ID == current,
+		Active:  d.ID == current,
+	}
+}
+
+// ListActivities returns every Activity known to the Activity manager
+func (k *KDEProvider) ListActivities() ([]*window.ActivityInfo, error) {
+	bus, err := k.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer bus.Close()
+
+	activities, current, err := kdeActivities(bus)
+	if err != nil {
+		return nil, err
+	}
+
+	infos := make([]*window.ActivityInfo, 0, len(activities))
+	for _, activity := range activities {
+		infos = append(infos, &window.ActivityInfo{
+			ID:      activity.ID,
+			Name:    activity.Name,
+			Icon:    activity.Icon,
+			Running: activity.State == kdeActivityRunning,
+			Current: activity.ID == current,
+		})
+	}
+	return infos, nil
+}
+
+// CurrentActivity returns the Activity in use
+func (k *KDEProvider) CurrentActivity() (*window.ActivityInfo, error) {
+	activities, err := k.ListActivities()
+	if err != nil {
+		return nil, err
+	}
+	for _, activity := range activities {
+		if activity.Current {
+			return activity, nil
+		}
+	}
+	return nil, fmt.Errorf("no current Activity found")
+}
@@ -0,0 +1,288 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/godbus/dbus/v5"
+)
+
+// fakeKWin stands in for KWin's scripting interface: running a script makes
+// it "report" the canned payload to whatever callback yawi exported
+type fakeKWin struct {
+	payload string
+	// scriptPath is where run is answered: /Scripting/ScriptN on Plasma 6, /N on Plasma 5
+	scriptPath dbus.ObjectPath
+	callback   *kdeCallback
+	script     string
+	calls      []string
+	// desktops and activities are in their D-Bus wire form; nil means the service is missing
+	desktops        [][]any
+	currentDesktop  string
+	activities      [][]any
+	currentActivity string
+}
+
+func (k *fakeKWin) call(dest string, path dbus.ObjectPath, method string, args []any, results ...any) error {
+	k.calls = append(k.calls, method)
+	switch method {
+	case "org.kde.kwin.Scripting.loadScript":
+		source, err := os.ReadFile(args[0].(string))
+		if err != nil {
+			return err
+		}
+		k.script = string(source)
+		return dbus.Store([]any{int32(7)}, results...)
+	case "org.kde.kwin.Scripting.unloadScript":
+		return dbus.Store([]any{true}, results...)
+	case "org.kde.ActivityManager.Activities.ListActivitiesWithInformation":
+		if k.activities == nil {
+			return fmt.Errorf("org.kde.ActivityManager is not running")
+		}
+		return dbus.Store([]any{k.activities}, results...)
+	case "org.kde.ActivityManager.Activities.CurrentActivity":
+		if k.activities == nil {
+			return fmt.Errorf("org.kde.ActivityManager is not running")
+		}
+		return dbus.Store([]any{k.currentActivity}, results...)
+	case "org.kde.kwin.Script.run":
+		if path != k.scriptPath {
+			return fmt.Errorf("no such object %s", path)
+		}
+		k.callback.Report(k.payload)
+		return nil
+	}
+	return fmt.Errorf("unknown method %s", method)
+}
+
+func (k *fakeKWin) property(dest string, path dbus.ObjectPath, name string) (dbus.Variant, error) {
+	if k.desktops == nil {
+		return dbus.Variant{}, fmt.Errorf("no such object %s", path)
+	}
+	switch name {
+	case "org.kde.KWin.VirtualDesktopManager.desktops":
+		return dbus.MakeVariant(k.desktops), nil
+	case "org.kde.KWin.VirtualDesktopManager.current":
+		return dbus.MakeVariant(k.currentDesktop), nil
+	}
+	return dbus.Variant{}, fmt.Errorf("unknown property %s", name)
+}
+
+func (k *fakeKWin) export(v any, path dbus.ObjectPath, iface string) error {
+	k.callback = v.(*kdeCallback)
+	return nil
+}
+
+func (k *fakeKWin) uniqueName() string { return ":1.42" }
+
+func (k *fakeKWin) Close() error { return nil }
+
+// kdeProviderWith returns a provider talking to kwin
+func kdeProviderWith(kwin *fakeKWin) *KDEProvider {
+	return &KDEProvider{bus: func() (kdeBus, error) { return kwin, nil }}
+}
+
+func TestKDEProvider_GetActiveWindowPlasma6(t *testing.T) {
+	kwin := &fakeKWin{
+		scriptPath: "/Scripting/Script7",
+		payload: `{"internalId":"{6d0b6b8e-9b6a-4b1c-9f59-0d3f3c1e2a77}","caption":"Dolphin","resourceClass":"org.kde.dolphin",
+			"resourceName":"dolphin","pid":1234,"desktops":[{"id":"b1f3","name":"Work"}],"desktop":null,
+			"output":"DP-1","screen":null,"geometry":{"x":0,"y":30,"width":1280,"height":770},"fullScreen":false}`,
+	}
+
+	info, err := kdeProviderWith(kwin).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	expected := &window.WindowInfo{
+		ID:         "6d0b6b8e-9b6a-4b1c-9f59-0d3f3c1e2a77",
+		Title:      "Dolphin",
+		Class:      "org.kde.dolphin",
+		Instance:   "dolphin",
+		PID:        1234,
+		Workspace:  "Work",
+		Monitor:    "DP-1",
+		Geometry:   &window.Geometry{X: 0, Y: 30, Width: 1280, Height: 770},
+		Fullscreen: window.Bool(false),
+		Pinned:     window.Bool(false),
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetActiveWindow() = %+v\nwant %+v", info, expected)
+	}
+
+	// The script calls back to our connection and is unloaded afterwards
+	if !strings.Contains(kwin.script, `callDBus(":1.42", "/org/alde/yawi/KWin", "org.alde.yawi.KWin", "Report"`) {
+		t.Errorf("script doesn't call back to yawi:\n%s", kwin.script)
+	}
+	if last := kwin.calls[len(kwin.calls)-1]; last != "org.kde.kwin.Scripting.unloadScript" {
+		t.Errorf("last call = %s, want the script unloaded", last)
+	}
+}
+
+func TestKDEProvider_GetActiveWindowPlasma5(t *testing.T) {
+	kwin := &fakeKWin{
+		scriptPath: "/7",
+		payload: `{"internalId":"{c2}","caption":"Konsole","resourceClass":"konsole","resourceName":"konsole",
+			"pid":99,"desktops":[],"desktop":2,"output":null,"screen":1,"geometry":{"x":0,"y":0,"width":0,"height":0}}`,
+	}
+
+	info, err := kdeProviderWith(kwin).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Workspace != "2" || info.Monitor != "1" || info.Class != "konsole" || info.Geometry != nil {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestKDEProvider_NoActiveWindow(t *testing.T) {
+	kwin := &fakeKWin{scriptPath: "/Scripting/Script7", payload: "null"}
+	if _, err := kdeProviderWith(kwin).GetActiveWindow(); err == nil {
+		t.Error("expected an error without an active window")
+	}
+}
+
+func TestKDEProvider_ScriptFailsToRun(t *testing.T) {
+	kwin := &fakeKWin{scriptPath: "/elsewhere"}
+	_, err := kdeProviderWith(kwin).GetActiveWindow()
+	if err == nil || !strings.Contains(err.Error(), "/Scripting/Script7") || !strings.Contains(err.Error(), "/7") {
+		t.Errorf("GetActiveWindow() error = %v, want both script paths mentioned", err)
+	}
+}
+
+// withDesktopsAndActivities gives kwin two desktops and two Activities, the second of each current
+func withDesktopsAndActivities(kwin *fakeKWin) *fakeKWin {
+	kwin.desktops = [][]any{{uint32(0), "d-1", "Mail"}, {uint32(1), "d-2", ""}}
+	kwin.currentDesktop = "d-2"
+	kwin.activities = [][]any{
+		{"a-1", "Default", "", "plasma", int32(2)},
+		{"a-2", "Focus", "Deep work", "", int32(4)},
+	}
+	kwin.currentActivity = "a-2"
+	return kwin
+}
+
+func TestKDEProvider_GetActiveWindowContext(t *testing.T) {
+	kwin := withDesktopsAndActivities(&fakeKWin{
+		scriptPath: "/Scripting/Script7",
+		payload:    `{"internalId":"{c2}","caption":"Kate","resourceClass":"org.kde.kate","activities":["a-2"]}`,
+	})
+
+	info, err := kdeProviderWith(kwin).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	expected := &window.KDEDetails{
+		DesktopID:    "d-2",
+		ActivityID:   "a-2",
+		ActivityName: "Focus",
+		Activities:   []string{"a-2"},
+	}
+	if !reflect.DeepEqual(info.KDE, expected) {
+		t.Errorf("KDE details = %+v, want %+v", info.KDE, expected)
+	}
+}
+
+func TestKDEProvider_GetActiveWindowOnSeveralDesktops(t *testing.T) {
+	tests := []struct {
+		name      string
+		payload   string
+		workspace string
+		desktop   string
+		named     string
+	}{
+		{
+			name:      "plasma 6 on the current desktop",
+			payload:   `{"internalId":"{c2}","caption":"Kate","desktops":[{"id":"d-1","name":"Mail"},{"id":"d-2","name":""}]}`,
+			workspace: "2",
+			desktop:   "d-2",
+		},
+		{
+			name:      "plasma 6 elsewhere",
+			payload:   `{"internalId":"{c2}","caption":"Kate","desktops":[{"id":"d-3","name":"Chat"},{"id":"d-1","name":"Mail"}]}`,
+			workspace: "Chat",
+			desktop:   "d-3",
+			named:     "Chat",
+		},
+		{
+			name:      "plasma 5",
+			payload:   `{"internalId":"{c2}","caption":"Kate","desktop":1}`,
+			workspace: "Mail",
+			desktop:   "d-1",
+			named:     "Mail",
+		},
+		{
+			name:      "plasma 5 unnamed",
+			payload:   `{"internalId":"{c2}","caption":"Kate","desktop":2}`,
+			workspace: "2",
+			desktop:   "d-2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kwin := withDesktopsAndActivities(&fakeKWin{scriptPath: "/Scripting/Script7", payload: tt.payload})
+
+			info, err := kdeProviderWith(kwin).GetActiveWindow()
+			if err != nil {
+				t.Fatalf("GetActiveWindow() error: %v", err)
+			}
+			if info.Workspace != tt.workspace || info.KDE.DesktopID != tt.desktop || info.KDE.DesktopName != tt.named {
+				t.Errorf("workspace %q, desktop %q (%q), want %q, %q (%q)",
+					info.Workspace, info.KDE.DesktopID, info.KDE.DesktopName, tt.workspace, tt.desktop, tt.named)
+			}
+		})
+	}
+}
+
+func TestKDEProvider_ListWorkspaces(t *testing.T) {
+	provider := kdeProviderWith(withDesktopsAndActivities(&fakeKWin{}))
+
+	workspaces, err := provider.ListWorkspaces()
+	if err != nil {
+		t.Fatalf("ListWorkspaces() error: %v", err)
+	}
+	expected := []*window.WorkspaceInfo{
+		{ID: 1, Name: "Mail"},
+		{ID: 2, Name: "2", Visible: true, Active: true},
+	}
+	if !reflect.DeepEqual(workspaces, expected) {
+		t.Errorf("ListWorkspaces() = %+v, want %+v", workspaces, expected)
+	}
+
+	active, err := provider.ActiveWorkspace()
+	if err != nil || active.ID != 2 {
+		t.Errorf("ActiveWorkspace() = %+v, %v, want desktop 2", active, err)
+	}
+}
+
+func TestKDEProvider_ListActivities(t *testing.T) {
+	provider := kdeProviderWith(withDesktopsAndActivities(&fakeKWin{}))
+
+	activities, err := provider.ListActivities()
+	if err != nil {
+		t.Fatalf("ListActivities() error: %v", err)
+	}
+	expected := []*window.ActivityInfo{
+		{ID: "a-1", Name: "Default", Icon: "plasma", Running: true},
+		{ID: "a-2", Name: "Focus", Current: true},
+	}
+	if !reflect.DeepEqual(activities, expected) {
+		t.Errorf("ListActivities() = %+v, want %+v", activities, expected)
+	}
+
+	current, err := provider.CurrentActivity()
+	if err != nil || current.ID != "a-2" {
+		t.Errorf("CurrentActivity() = %+v, %v, want a-2", current, err)
+	}
+}
+
+func TestKDEProvider_ActivitiesUnavailable(t *testing.T) {
+	if _, err := kdeProviderWith(&fakeKWin{}).ListActivities(); err == nil {
+		t.Error("expected an error without the Activity manager")
+	}
+}
@@ -1,16 +1,18 @@
 package providers
 
 import (
-	"encoding/json"
 	"fmt"
-	"net"
 	"os"
+	"strconv"
 
 	"github.com/alde/yawi/pkg/window"
 )
 
 // SwayProvider implements window information retrieval for Sway
-type SwayProvider struct{}
+type SwayProvider struct {
+	// socketPath locates the i3-ipc socket; nil means $SWAYSOCK
+	socketPath func() (string, error)
+}
 
 // Name returns the provider name
 func (s *SwayProvider) Name() string {
@@ -19,33 +21,38 @@ func (s *SwayProvider) Name() string {
 
 // swayNode represents the JSON structure of Sway's tree nodes
 type swayNode struct {
-	ID                 int        `json:"id"`
-	Name               *string    `json:"name"`
-	Type               string     `json:"type"`
-	Border             string     `json:"border"`
-	CurrentBorderWidth int        `json:"current_border_width"`
-	Layout             string     `json:"layout"`
-	Orientation        string     `json:"orientation"`
-	Percent            *float64   `json:"percent"`
-	Rect               swayRect   `json:"rect"`
-	WindowRect         swayRect   `json:"window_rect"`
-	DecoRect           swayRect   `json:"deco_rect"`
-	Geometry           swayRect   `json:"geometry"`
-	Urgent             bool       `json:"urgent"`
-	Focused            bool       `json:"focused"`
-	Focus              []int      `json:"focus"`
+	ID                 int         `json:"id"`
+	Name               *string     `json:"name"`
+	Type               string      `json:"type"`
+	Border             string      `json:"border"`
+	CurrentBorderWidth int         `json:"current_border_width"`
+	Layout             string      `json:"layout"`
+	Orientation        string      `json:"orientation"`
+	Percent            *float64    `json:"percent"`
+	Rect               swayRect    `json:"rect"`
+	WindowRect         swayRect    `json:"window_rect"`
+	DecoRect           swayRect    `json:"deco_rect"`
+	Geometry           swayRect    `json:"geometry"`
+	Urgent             flexBool    `json:"urgent"`
+	Focused            flexBool    `json:"focused"`
+	Focus              []int       `json:"focus"`
 	Nodes              []*swayNode `json:"nodes"`
 	FloatingNodes      []*swayNode `json:"floating_nodes"`
-	Sticky             bool       `json:"sticky"`
-	Representation     *string    `json:"representation"`
-	AppID              *string    `json:"app_id"`
+	Sticky             flexBool    `json:"sticky"`
+	Representation     *string     `json:"representation"`
+	AppID              *string     `json:"app_id"`
 	WindowProperties   *struct {
 		Class        *string `json:"class"`
 		Instance     *string `json:"instance"`
 		Title        *string `json:"title"`
 		TransientFor *int    `json:"transient_for"`
 	} `json:"window_properties"`
-	PID *int `json:"pid"`
+	PID         *int      `json:"pid"`
+	Shell       *string   `json:"shell"`
+	InhibitIdle *flexBool `json:"inhibit_idle"`
+	Marks       []string  `json:"marks"`
+	// Output is set on workspaces
+	Output *string `json:"output"`
 }
 
 type swayRect struct {
@@ -57,106 +64,207 @@ type swayRect struct {
 
 // GetActiveWindow retrieves the currently active window from Sway
 func (s *SwayProvider) GetActiveWindow() (*window.WindowInfo, error) {
-	socketPath := os.Getenv("SWAYSOCK")
-	if socketPath == "" {
-		return nil, fmt.Errorf("SWAYSOCK environment variable not found - are we running under Sway?")
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
 	}
+	defer client.Close()
 
-	conn, err := net.Dial("unix", socketPath)
+	root, err := client.tree()
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to Sway socket: %w", err)
+		return nil, err
 	}
-	defer conn.Close()
 
-	// Send i3-ipc GET_TREE message (magic bytes + payload length + message type)
-	message := []byte("i3-ipc")
-	message = append(message, 0, 0, 0, 0) // payload length (0)
-	message = append(message, 4, 0, 0, 0) // GET_TREE message type
+	focus := findSwayFocus(root)
+	if focus == nil {
+		return nil, fmt.Errorf("no focused window found in i3-ipc tree")
+	}
 
-	_, err = conn.Write(message)
+	return focus.toWindowInfo(), nil
+}
+
+// ListWindows returns every window in the tree, including hidden scratchpad windows
+func (s *SwayProvider) ListWindows() ([]*window.WindowInfo, error) {
+	client, err := s.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to send i3-ipc request: %w", err)
+		return nil, err
 	}
+	defer client.Close()
 
-	// Read response header (14 bytes: 6 magic + 4 length + 4 type)
-	header := make([]byte, 14)
-	_, err = conn.Read(header)
+	root, err := client.tree()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Sway response header: %w", err)
+		return nil, err
 	}
 
-	// Extract payload length from header
-	payloadLength := int(header[6]) | int(header[7])<<8 | int(header[8])<<16 | int(header[9])<<24
+	var windows []*window.WindowInfo
+	walkSwayViews(root, func(view swayView) bool {
+		windows = append(windows, view.toWindowInfo())
+		return true
+	})
+	return windows, nil
+}
 
-	// Read the JSON payload
-	payload := make([]byte, payloadLength)
-	_, err = conn.Read(payload)
+// CompositorVersion returns the version reported by GET_VERSION
+func (s *SwayProvider) CompositorVersion() (string, error) {
+	client, err := s.connect()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read Sway JSON payload: %w", err)
+		return "", err
 	}
+	defer client.Close()
 
-	var root swayNode
-	if err := json.Unmarshal(payload, &root); err != nil {
-		return nil, fmt.Errorf("failed to decode Sway JSON response: %w", err)
+	version, err := client.version()
+	if err != nil {
+		return "", err
 	}
+	if version.HumanReadable != "" {
+		return version.HumanReadable, nil
+	}
+	return fmt.Sprintf("%d.%d.%d", version.Major, version.Minor, version.Patch), nil
+}
 
-	focused := s.findFocusedNode(&root)
-	if focused == nil {
-		return nil, fmt.Errorf("no focused window found in Sway tree")
+// toWindowInfo converts a Sway container into the common window structure.
+// Native Wayland windows are identified by app_id, XWayland ones by their X11 class.
+func (n *swayNode) toWindowInfo(workspace string) *window.WindowInfo {
+	info := &window.WindowInfo{
+		ID:        strconv.Itoa(n.ID),
+		Workspace: workspace,
+		Sway:      &window.SwayDetails{},
 	}
 
-	var title, class string
-	var pid int
+	if n.Name != nil {
+		info.Title = *n.Name
+	}
+	if n.AppID != nil {
+		info.AppID = *n.AppID
+		info.Class = *n.AppID
+	}
 
-	if focused.WindowProperties != nil {
-		if focused.WindowProperties.Title != nil {
-			title = *focused.WindowProperties.Title
+	if n.WindowProperties != nil {
+		if n.WindowProperties.Title != nil {
+			info.Title = *n.WindowProperties.Title
 		}
-		if focused.WindowProperties.Class != nil {
-			class = *focused.WindowProperties.Class
+		if n.WindowProperties.Class != nil {
+			info.Class = *n.WindowProperties.Class
+		}
+		if n.WindowProperties.Instance != nil {
+			info.Instance = *n.WindowProperties.Instance
 		}
 	}
 
-	if focused.PID != nil {
-		pid = *focused.PID
+	if n.PID != nil {
+		info.PID = *n.PID
+	}
+	if n.Shell != nil {
+		info.Sway.Shell = *n.Shell
+		info.XWayland = window.Bool(*n.Shell == "xwayland")
 	}
+	if n.InhibitIdle != nil {
+		info.Sway.InhibitIdle = bool(*n.InhibitIdle)
+	}
+	info.Sway.Marks = n.Marks
+
+	return info
+}
 
-	// Try to get workspace information
-	workspace := ""
-	if focused.Representation != nil {
-		workspace = *focused.Representation
-	} else if focused.Name != nil {
-		workspace = *focused.Name
+// connect opens an i3-ipc connection to the running Sway (or i3) instance
+func (s *SwayProvider) connect() (*i3Client, error) {
+	lookup := s.socketPath
+	if lookup == nil {
+		lookup = swaySocketPath
 	}
 
-	return &window.WindowInfo{
-		Title:     title,
-		Class:     class,
-		PID:       pid,
-		Workspace: workspace,
-	}, nil
+	socketPath, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	return newI3Client(socketPath)
 }
 
-// findFocusedNode recursively searches the Sway tree for the focused window
-func (s *SwayProvider) findFocusedNode(node *swayNode) *swayNode {
-	// Check if this node is focused and has window properties
-	if node.Focused && node.WindowProperties != nil {
-		return node
+// swaySocketPath returns the IPC socket advertised by Sway
+func swaySocketPath() (string, error) {
+	socketPath := os.Getenv("SWAYSOCK")
+	if socketPath == "" {
+		return "", fmt.Errorf("SWAYSOCK environment variable not found - are we running under Sway?")
 	}
+	return socketPath, nil
+}
+
+// swayView is a window together with the workspace and output holding it
+type swayView struct {
+	window    *swayNode
+	workspace *swayNode
+	output    *swayNode
+}
+
+// swayScratchpad is the hidden workspace holding scratchpad windows
+const swayScratchpad = "__i3_scratch"
+
+// walkSwayViews visits every window in the tree, keeping track of the
+// workspace and output nodes passed on the way down. Returning false from
+// visit stops the walk.
+func walkSwayViews(root *swayNode, visit func(view swayView) bool) {
+	var walk func(node *swayNode, view swayView) bool
+	walk = func(node *swayNode, view swayView) bool {
+		switch node.Type {
+		case "output":
+			view.output = node
+		case "workspace":
+			view.workspace = node
+		}
+
+		if node.isView() {
+			view.window = node
+			if !visit(view) {
+				return false
+			}
+		}
 
-	// Recursively search child nodes
-	for _, child := range node.Nodes {
-		if found := s.findFocusedNode(child); found != nil {
-			return found
+		for _, child := range node.Nodes {
+			if !walk(child, view) {
+				return false
+			}
+		}
+		for _, floating := range node.FloatingNodes {
+			if !walk(floating, view) {
+				return false
+			}
 		}
+		return true
 	}
 
-	// Search floating nodes as well
-	for _, floating := range node.FloatingNodes {
-		if found := s.findFocusedNode(floating); found != nil {
-			return found
+	walk(root, swayView{})
+}
+
+// findSwayFocus returns the focused window, or nil if no window has focus
+func findSwayFocus(root *swayNode) *swayView {
+	var focus *swayView
+	walkSwayViews(root, func(view swayView) bool {
+		if view.window.Focused {
+			focus = &view
+			return false
 		}
+		return true
+	})
+	return focus
+}
+
+// isView reports whether the node is an actual window rather than a split container.
+// XWayland windows carry window_properties, native Wayland ones an app_id.
+func (n *swayNode) isView() bool {
+	return n.WindowProperties != nil || n.AppID != nil
+}
+
+// toWindowInfo converts the window and its ancestors into the common window structure
+func (v *swayView) toWindowInfo() *window.WindowInfo {
+	workspace := swayNodeName(v.workspace)
+
+	// Scratchpad windows live on a hidden workspace of the pseudo output __i3
+	monitor := ""
+	if workspace != swayScratchpad {
+		monitor = swayNodeName(v.output)
 	}
 
-	return nil
-}
\ No newline at end of file
+	info := v.window.toWindowInfo(workspace)
+	info.Monitor = monitor
+	return info
+}
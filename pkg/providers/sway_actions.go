@@ -0,0 +1,155 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SwayCriteria selects windows for a command, e.g. [app_id="foot" title="vim"].
+// AppID, Class and Title are regular expressions; zero values are left out.
+type SwayCriteria struct {
+	ConID int
+	AppID string
+	Class string
+	Title string
+}
+
+// String renders the criteria in Sway's bracket syntax
+func (c SwayCriteria) String() string {
+	var parts []string
+	if c.ConID != 0 {
+		parts = append(parts, "con_id="+strconv.Itoa(c.ConID))
+	}
+	if c.AppID != "" {
+		parts = append(parts, "app_id="+swayQuote(c.AppID))
+	}
+	if c.Class != "" {
+		parts = append(parts, "class="+swayQuote(c.Class))
+	}
+	if c.Title != "" {
+		parts = append(parts, "title="+swayQuote(c.Title))
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
+
+// swayQuote wraps a command argument in double quotes, escaping as Sway expects
+func swayQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// swayCriteriaFor turns a window ID into criteria. Numeric IDs are con_ids,
+// anything already in brackets is passed through as ready-made criteria.
+func swayCriteriaFor(id string) (string, error) {
+	id = strings.TrimSpace(id)
+	if strings.HasPrefix(id, "[") && strings.HasSuffix(id, "]") {
+		return id, nil
+	}
+
+	conID, err := strconv.Atoi(id)
+	if err != nil || conID <= 0 {
+		return "", fmt.Errorf("invalid window ID %q: expected a con_id or [criteria]", id)
+	}
+	return SwayCriteria{ConID: conID}.String(), nil
+}
+
+// RunCommand runs one or more commands (separated by ; or ,) and reports every
+// command Sway rejected as an error
+func (s *SwayProvider) RunCommand(command string) error {
+	client, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	results, err := client.runCommand(command)
+	if err != nil {
+		return err
+	}
+
+	var failures []string
+	for _, result := range results {
+		if result.Success {
+			continue
+		}
+		message := result.Error
+		if message == "" {
+			message = "unknown error"
+		}
+		if result.ParseError {
+			message = "parse error: " + message
+		}
+		failures = append(failures, message)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("command %q failed: %s", command, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// runOnWindow runs command against the window(s) selected by id
+func (s *SwayProvider) runOnWindow(id, command string) error {
+	criteria, err := swayCriteriaFor(id)
+	if err != nil {
+		return err
+	}
+	return s.RunCommand(criteria + " " + command)
+}
+
+// SelectWindows builds criteria matching windows by app_id, class and title
+// patterns, usable anywhere a window ID is expected
+func (s *SwayProvider) SelectWindows(appID, class, title string) (string, error) {
+	if appID == "" && class == "" && title == "" {
+		return "", fmt.Errorf("no criteria given")
+	}
+	return SwayCriteria{AppID: appID, Class: class, Title: title}.String(), nil
+}
+
+// FocusWindow focuses the selected window
+func (s *SwayProvider) FocusWindow(id string) error {
+	return s.runOnWindow(id, "focus")
+}
+
+// CloseWindow kills the selected window(s)
+func (s *SwayProvider) CloseWindow(id string) error {
+	return s.runOnWindow(id, "kill")
+}
+
+// MoveWindowToWorkspace moves the selected window to a workspace and focuses it there
+func (s *SwayProvider) MoveWindowToWorkspace(id, workspace string) error {
+	criteria, err := swayCriteriaFor(id)
+	if err != nil {
+		return err
+	}
+	return s.RunCommand(fmt.Sprintf("%s move container to workspace %s; %s focus", criteria, swayQuote(workspace), criteria))
+}
+
+// MoveWindowToWorkspaceSilent moves the selected window to a workspace without following it
+func (s *SwayProvider) MoveWindowToWorkspaceSilent(id, workspace string) error {
+	return s.runOnWindow(id, "move container to workspace "+swayQuote(workspace))
+}
+
+// MoveWindowToOutput moves the selected window to another output
+func (s *SwayProvider) MoveWindowToOutput(id, output string) error {
+	return s.runOnWindow(id, "move container to output "+swayQuote(output))
+}
+
+// ToggleFloating switches the selected window between tiled and floating
+func (s *SwayProvider) ToggleFloating(id string) error {
+	return s.runOnWindow(id, "floating toggle")
+}
+
+// ToggleFullscreen switches the selected window in and out of fullscreen
+func (s *SwayProvider) ToggleFullscreen(id string) error {
+	return s.runOnWindow(id, "fullscreen toggle")
+}
+
+// ShowScratchpad toggles the selected scratchpad window, or cycles through the
+// scratchpad when id is empty
+func (s *SwayProvider) ShowScratchpad(id string) error {
+	if id == "" {
+		return s.RunCommand("scratchpad show")
+	}
+	return s.runOnWindow(id, "scratchpad show")
+}
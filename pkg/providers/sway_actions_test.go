@@ -0,0 +1,89 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSwayCriteria_String(t *testing.T) {
+	tests := []struct {
+		criteria SwayCriteria
+		expected string
+	}{
+		{SwayCriteria{ConID: 42}, `[con_id=42]`},
+		{SwayCriteria{AppID: "foot"}, `[app_id="foot"]`},
+		{SwayCriteria{Class: "^Firefox$", Title: `say "hi"`}, `[class="^Firefox$" title="say \"hi\""]`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.criteria.String(); got != tt.expected {
+			t.Errorf("%+v.String() = %s, want %s", tt.criteria, got, tt.expected)
+		}
+	}
+}
+
+func TestSwayCriteriaFor(t *testing.T) {
+	if got, err := swayCriteriaFor("17"); err != nil || got != "[con_id=17]" {
+		t.Errorf("swayCriteriaFor(17) = %q, %v", got, err)
+	}
+	if got, err := swayCriteriaFor(`[app_id="foot"]`); err != nil || got != `[app_id="foot"]` {
+		t.Errorf("criteria should pass through, got %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "0x1234", "-3"} {
+		if _, err := swayCriteriaFor(bad); err == nil {
+			t.Errorf("swayCriteriaFor(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSwayProvider_Actions(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3RunCommand: func(payload string) string {
+			if strings.Contains(payload, "con_id=404") {
+				return `[{"success":false,"error":"No matching node."}]`
+			}
+			if strings.Contains(payload, "; ") {
+				return `[{"success":true},{"success":true}]`
+			}
+			return `[{"success":true}]`
+		},
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	provider := &SwayProvider{}
+	tests := []struct {
+		name     string
+		action   func() error
+		expected string
+	}{
+		{"focus", func() error { return provider.FocusWindow("12") }, `[con_id=12] focus`},
+		{"kill", func() error { return provider.CloseWindow("12") }, `[con_id=12] kill`},
+		{"move", func() error { return provider.MoveWindowToWorkspace("12", "2: web") },
+			`[con_id=12] move container to workspace "2: web"; [con_id=12] focus`},
+		{"move silently", func() error { return provider.MoveWindowToWorkspaceSilent("12", "3") },
+			`[con_id=12] move container to workspace "3"`},
+		{"move to output", func() error { return provider.MoveWindowToOutput("12", "HDMI-A-1") },
+			`[con_id=12] move container to output "HDMI-A-1"`},
+		{"floating", func() error { return provider.ToggleFloating("12") }, `[con_id=12] floating toggle`},
+		{"fullscreen", func() error { return provider.ToggleFullscreen("12") }, `[con_id=12] fullscreen toggle`},
+		{"scratchpad cycle", func() error { return provider.ShowScratchpad("") }, `scratchpad show`},
+		{"scratchpad by criteria", func() error { return provider.ShowScratchpad(`[app_id="foot"]`) },
+			`[app_id="foot"] scratchpad show`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.action(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := <-fake.requests; got != tt.expected {
+				t.Errorf("sent %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	err := provider.FocusWindow("404")
+	if err == nil || !strings.Contains(err.Error(), "No matching node.") {
+		t.Errorf("FocusWindow() should surface Sway's error, got %v", err)
+	}
+}
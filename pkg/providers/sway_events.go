@@ -0,0 +1,324 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// i3-ipc event types, as sent with the event bit set
+const (
+	i3EventWorkspace = i3ipcEventBit | 0
+	i3EventOutput    = i3ipcEventBit | 1
+	i3EventMode      = i3ipcEventBit | 2
+	i3EventWindow    = i3ipcEventBit | 3
+	i3EventShutdown  = i3ipcEventBit | 6
+)
+
+// SwayEvent is an event received over a subscribed i3-ipc connection
+type SwayEvent interface {
+	// EventName returns the subscription name of the event, e.g. "window"
+	EventName() string
+}
+
+// SwayWindowEvent is sent when a window is created, focused, retitled, moved, closed, ...
+type SwayWindowEvent struct {
+	// Change is one of new, close, focus, title, fullscreen_mode, move, floating, urgent or mark
+	Change string
+	ConID  int
+	// Focused reports whether the container had focus when the event was sent
+	Focused bool
+	Window  *window.WindowInfo
+}
+
+// SwayWorkspaceEvent is sent when workspaces are focused, created, emptied, renamed, ...
+type SwayWorkspaceEvent struct {
+	// Change is one of init, empty, focus, move, rename, urgent or reload
+	Change  string
+	Current string
+	Old     string
+	// Output is the output the current workspace is on, when Sway reports it
+	Output string
+}
+
+// SwayOutputEvent is sent when outputs are added, removed or reconfigured
+type SwayOutputEvent struct {
+	Change string
+}
+
+// SwayModeEvent is sent when the binding mode changes; Change holds the new mode name
+type SwayModeEvent struct {
+	Change      string
+	PangoMarkup bool
+}
+
+// SwayShutdownEvent is sent right before the window manager exits or restarts
+type SwayShutdownEvent struct {
+	Change string
+}
+
+func (SwayWindowEvent) EventName() string    { return "window" }
+func (SwayWorkspaceEvent) EventName() string { return "workspace" }
+func (SwayOutputEvent) EventName() string    { return "output" }
+func (SwayModeEvent) EventName() string      { return "mode" }
+func (SwayShutdownEvent) EventName() string  { return "shutdown" }
+
+// swayEventNames are the subscriptions yawi decodes
+var swayEventNames = []string{"window", "workspace", "output", "mode", "shutdown"}
+
+// subscribe asks for the given events on this connection. Afterwards the
+// connection should only be used to read events.
+func (c *i3Client) subscribe(names ...string) error {
+	payload, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := c.requestJSON(i3Subscribe, payload, &result); err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("i3-ipc subscription to %v was rejected", names)
+	}
+	return nil
+}
+
+// events decodes events from a subscribed connection until it closes or ctx
+// is cancelled. The connection is closed when the stream ends.
+func (c *i3Client) events(ctx context.Context) <-chan SwayEvent {
+	ch := make(chan SwayEvent)
+	go func() {
+		defer close(ch)
+		defer c.Close()
+
+		stop := context.AfterFunc(ctx, func() { c.Close() })
+		defer stop()
+
+		for {
+			msgType, payload, err := c.readMessage()
+			if err != nil {
+				return
+			}
+
+			event, err := parseSwayEvent(msgType, payload)
+			if err != nil || event == nil {
+				continue
+			}
+
+			select {
+			case ch <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// parseSwayEvent decodes an event payload, returning nil for event types yawi ignores
+func parseSwayEvent(msgType uint32, payload []byte) (SwayEvent, error) {
+	switch msgType {
+	case i3EventWindow:
+		var raw struct {
+			Change    string    `json:"change"`
+			Container *swayNode `json:"container"`
+		}
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return nil, err
+		}
+		event := SwayWindowEvent{Change: raw.Change}
+		if raw.Container != nil {
+			event.ConID = raw.Container.ID
+			event.Focused = bool(raw.Container.Focused)
+			event.Window = raw.Container.toWindowInfo("")
+		}
+		return event, nil
+
+	case i3EventWorkspace:
+		var raw struct {
+			Change  string    `json:"change"`
+			Current *swayNode `json:"current"`
+			Old     *swayNode `json:"old"`
+		}
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return nil, err
+		}
+		event := SwayWorkspaceEvent{
+			Change:  raw.Change,
+			Current: swayNodeName(raw.Current),
+			Old:     swayNodeName(raw.Old),
+		}
+		if raw.Current != nil && raw.Current.Output != nil {
+			event.Output = *raw.Current.Output
+		}
+		return event, nil
+
+	case i3EventOutput:
+		var raw struct {
+			Change string `json:"change"`
+		}
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return nil, err
+		}
+		return SwayOutputEvent{Change: raw.Change}, nil
+
+	case i3EventMode:
+		var raw struct {
+			Change      string `json:"change"`
+			PangoMarkup bool   `json:"pango_markup"`
+		}
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return nil, err
+		}
+		return SwayModeEvent{Change: raw.Change, PangoMarkup: raw.PangoMarkup}, nil
+
+	case i3EventShutdown:
+		var raw struct {
+			Change string `json:"change"`
+		}
+		if err := json.Unmarshal(payload, &raw); err != nil {
+			return nil, err
+		}
+		return SwayShutdownEvent{Change: raw.Change}, nil
+	}
+
+	return nil, nil
+}
+
+// swayNodeName returns a node's name, or "" for a missing node
+func swayNodeName(node *swayNode) string {
+	if node == nil || node.Name == nil {
+		return ""
+	}
+	return *node.Name
+}
+
+// Events subscribes to window, workspace, output, mode and shutdown events on
+// a dedicated connection. The channel is closed when Sway exits or ctx ends.
+func (s *SwayProvider) Events(ctx context.Context) (<-chan SwayEvent, error) {
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.subscribe(swayEventNames...); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client.events(ctx), nil
+}
+
+// focusedWorkspace returns the name and output of the focused workspace, or
+// empty strings if they can't be determined
+func (s *SwayProvider) focusedWorkspace() (name, output string) {
+	client, err := s.connect()
+	if err != nil {
+		return "", ""
+	}
+	defer client.Close()
+
+	workspaces, err := client.workspaces()
+	if err != nil {
+		return "", ""
+	}
+	for _, ws := range workspaces {
+		if ws.Focused {
+			return ws.Name, ws.Output
+		}
+	}
+	return "", ""
+}
+
+// Watch reports active window changes from Sway's event stream. Focus and
+// title changes are taken straight from the events; only changes that can
+// move focus implicitly (closing windows, switching workspaces) fetch the tree.
+func (s *SwayProvider) Watch(ctx context.Context) (<-chan window.Event, error) {
+	events, err := s.Events(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := s.BindingMode()
+	if err != nil {
+		mode = ""
+	}
+
+	out := make(chan window.Event)
+	go func() {
+		defer close(out)
+
+		emitter := newWatchEmitter(ctx, out, mode)
+
+		// refresh reads the focused window from the tree
+		refresh := func() bool {
+			info, err := s.GetActiveWindow()
+			if err != nil {
+				info = nil
+			}
+			return emitter.setWindow(info)
+		}
+
+		// Window events don't say where the window is, so track the focused
+		// workspace and its output to fill in what the tree would report
+		workspace, monitor := s.focusedWorkspace()
+		if !refresh() {
+			return
+		}
+
+		for event := range events {
+			keepGoing := true
+			switch e := event.(type) {
+			case SwayWorkspaceEvent:
+				switch {
+				case e.Change == "focus":
+					workspace = e.Current
+					if e.Output != "" {
+						monitor = e.Output
+					} else {
+						_, monitor = s.focusedWorkspace()
+					}
+					keepGoing = refresh()
+				case e.Change == "move" && e.Current == workspace:
+					// The focused workspace was sent to another output
+					if e.Output != "" {
+						monitor = e.Output
+					}
+					keepGoing = refresh()
+				}
+			case SwayWindowEvent:
+				switch e.Change {
+				case "focus", "title":
+					if e.Focused && e.Window != nil {
+						e.Window.Workspace = workspace
+						e.Window.Monitor = monitor
+						keepGoing = emitter.setWindow(e.Window)
+					}
+				case "close", "move", "floating":
+					keepGoing = refresh()
+				}
+			case SwayModeEvent:
+				keepGoing = emitter.setMode(e.Change)
+			}
+			if !keepGoing {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// BindingMode returns the name of the active binding mode
+func (s *SwayProvider) BindingMode() (string, error) {
+	client, err := s.connect()
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+
+	return client.bindingState()
+}
@@ -0,0 +1,215 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSwayEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgType  uint32
+		payload  string
+		expected SwayEvent
+	}{
+		{
+			name:     "workspace focus",
+			msgType:  i3EventWorkspace,
+			payload:  `{"change":"focus","current":{"id":10,"type":"workspace","name":"2: web"},"old":{"id":5,"type":"workspace","name":"1"}}`,
+			expected: SwayWorkspaceEvent{Change: "focus", Current: "2: web", Old: "1"},
+		},
+		{
+			name:     "workspace init without old",
+			msgType:  i3EventWorkspace,
+			payload:  `{"change":"init","current":{"id":11,"type":"workspace","name":"3"},"old":null}`,
+			expected: SwayWorkspaceEvent{Change: "init", Current: "3"},
+		},
+		{
+			name:     "workspace focus with output",
+			msgType:  i3EventWorkspace,
+			payload:  `{"change":"focus","current":{"id":12,"type":"workspace","name":"4","output":"HDMI-A-1"},"old":null}`,
+			expected: SwayWorkspaceEvent{Change: "focus", Current: "4", Output: "HDMI-A-1"},
+		},
+		{
+			name:     "output",
+			msgType:  i3EventOutput,
+			payload:  `{"change":"unspecified"}`,
+			expected: SwayOutputEvent{Change: "unspecified"},
+		},
+		{
+			name:     "mode",
+			msgType:  i3EventMode,
+			payload:  `{"change":"resize","pango_markup":true}`,
+			expected: SwayModeEvent{Change: "resize", PangoMarkup: true},
+		},
+		{
+			name:     "shutdown",
+			msgType:  i3EventShutdown,
+			payload:  `{"change":"exit"}`,
+			expected: SwayShutdownEvent{Change: "exit"},
+		},
+		{
+			name:     "ignored tick",
+			msgType:  i3ipcEventBit | 7,
+			payload:  `{"first":true,"payload":""}`,
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseSwayEvent(tt.msgType, []byte(tt.payload))
+			if err != nil {
+				t.Fatalf("parseSwayEvent() error: %v", err)
+			}
+			if !reflect.DeepEqual(event, tt.expected) {
+				t.Errorf("parseSwayEvent() = %#v, want %#v", event, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseSwayEvent_Window(t *testing.T) {
+	payload := `{"change":"focus","container":{"id":42,"type":"con","focused":true,"pid":7,
+		"window_properties":{"class":"Firefox","title":"Mozilla Firefox"}}}`
+
+	event, err := parseSwayEvent(i3EventWindow, []byte(payload))
+	if err != nil {
+		t.Fatalf("parseSwayEvent() error: %v", err)
+	}
+
+	windowEvent, ok := event.(SwayWindowEvent)
+	if !ok {
+		t.Fatalf("expected SwayWindowEvent, got %T", event)
+	}
+	if windowEvent.Change != "focus" || windowEvent.ConID != 42 || !windowEvent.Focused {
+		t.Errorf("unexpected event: %+v", windowEvent)
+	}
+	if windowEvent.Window == nil || windowEvent.Window.Class != "Firefox" || windowEvent.Window.PID != 7 {
+		t.Errorf("unexpected window: %+v", windowEvent.Window)
+	}
+}
+
+func TestSwayProvider_Events(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3Subscribe: i3Reply(`{"success":true}`),
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider := &SwayProvider{}
+	events, err := provider.Events(ctx)
+	if err != nil {
+		t.Fatalf("Events() error: %v", err)
+	}
+	if got := <-fake.requests; got != `["window","workspace","output","mode","shutdown"]` {
+		t.Errorf("SUBSCRIBE payload = %s", got)
+	}
+
+	conn := <-fake.subscribed
+	writeI3Message(conn, i3EventMode, `{"change":"resize","pango_markup":false}`)
+	writeI3Message(conn, i3EventShutdown, `{"change":"exit"}`)
+
+	if event := <-events; event != (SwayModeEvent{Change: "resize"}) {
+		t.Errorf("first event = %#v", event)
+	}
+	if event := <-events; event != (SwayShutdownEvent{Change: "exit"}) {
+		t.Errorf("second event = %#v", event)
+	}
+
+	// The stream ends once the window manager closes the connection
+	conn.Close()
+	if _, ok := <-events; ok {
+		t.Error("expected the event channel to close")
+	}
+}
+
+func TestSwayProvider_SubscribeRejected(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3Subscribe: i3Reply(`{"success":false}`),
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	provider := &SwayProvider{}
+	if _, err := provider.Events(context.Background()); err == nil {
+		t.Error("expected an error when the subscription is rejected")
+	}
+}
+
+func TestSwayProvider_WatchMode(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3Subscribe:       i3Reply(`{"success":true}`),
+		i3GetBindingState: i3Reply(`{"name":"default"}`),
+		i3GetTree: i3Reply(`{"id":1,"type":"root","nodes":[{"id":2,"type":"output","name":"eDP-1","nodes":[
+			{"id":3,"type":"workspace","name":"1","nodes":[{"id":4,"type":"con","name":"foot","focused":true,"app_id":"foot"}]}]}]}`),
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider := &SwayProvider{}
+	events, err := provider.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+
+	first := <-events
+	if first.Mode != "default" || first.Window == nil || first.Window.AppID != "foot" {
+		t.Fatalf("first event = %+v", first)
+	}
+
+	conn := <-fake.subscribed
+	writeI3Message(conn, i3EventMode, `{"change":"resize","pango_markup":false}`)
+	// Entering the same mode again is not a change
+	writeI3Message(conn, i3EventMode, `{"change":"resize","pango_markup":false}`)
+	writeI3Message(conn, i3EventMode, `{"change":"default","pango_markup":false}`)
+
+	second := <-events
+	if second.Mode != "resize" || !reflect.DeepEqual(second.Window, first.Window) {
+		t.Errorf("second event = %+v", second)
+	}
+	if third := <-events; third.Mode != "default" {
+		t.Errorf("third event = %+v", third)
+	}
+}
+
+func TestSwayProvider_WatchKeepsMonitor(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3Subscribe:       i3Reply(`{"success":true}`),
+		i3GetBindingState: i3Reply(`{"name":"default"}`),
+		i3GetWorkspaces:   i3Reply(`[{"id":3,"num":1,"name":"1","focused":true,"output":"eDP-1"}]`),
+		i3GetTree: i3Reply(`{"id":1,"type":"root","nodes":[{"id":2,"type":"output","name":"eDP-1","nodes":[
+			{"id":3,"type":"workspace","name":"1","nodes":[{"id":4,"type":"con","name":"foot","focused":true,"app_id":"foot"}]}]}]}`),
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	provider := &SwayProvider{}
+	events, err := provider.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+
+	first := <-events
+	if first.Window == nil || first.Window.Monitor != "eDP-1" || first.Window.Workspace != "1" {
+		t.Fatalf("first event = %+v", first.Window)
+	}
+
+	conn := <-fake.subscribed
+	writeI3Message(conn, i3EventWindow, `{"change":"focus","container":{"id":5,"type":"con","name":"kitty","focused":true,"app_id":"kitty"}}`)
+
+	second := <-events
+	if second.Window == nil || second.Window.AppID != "kitty" {
+		t.Fatalf("second event = %+v", second.Window)
+	}
+	if second.Window.Monitor != "eDP-1" || second.Window.Workspace != "1" {
+		t.Errorf("focus event window on %q/%q, want eDP-1/1", second.Window.Monitor, second.Window.Workspace)
+	}
+}
@@ -0,0 +1,73 @@
+package providers
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// ListMarks returns every mark currently set
+func (s *SwayProvider) ListMarks() ([]string, error) {
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	return client.marks()
+}
+
+// AddMark puts mark on the selected window. Marks are unique, so a mark set
+// on another window moves over; the window's other marks are kept.
+func (s *SwayProvider) AddMark(id, mark string) error {
+	if mark == "" {
+		return fmt.Errorf("no mark given")
+	}
+	return s.runOnWindow(id, "mark --add "+swayQuote(mark))
+}
+
+// RemoveMark takes mark off the selected window
+func (s *SwayProvider) RemoveMark(id, mark string) error {
+	if mark == "" {
+		return fmt.Errorf("no mark given")
+	}
+	return s.runOnWindow(id, "unmark "+swayQuote(mark))
+}
+
+// FocusMark focuses the window carrying mark. The window is looked up in the
+// tree and focused by con_id, since con_mark criteria would treat the mark as
+// a regular expression.
+func (s *SwayProvider) FocusMark(mark string) error {
+	client, err := s.connect()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	marks, err := client.marks()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(marks, mark) {
+		return fmt.Errorf("no window is marked %q", mark)
+	}
+
+	root, err := client.tree()
+	if err != nil {
+		return err
+	}
+
+	conID := 0
+	walkSwayViews(root, func(view swayView) bool {
+		if slices.Contains(view.window.Marks, mark) {
+			conID = view.window.ID
+			return false
+		}
+		return true
+	})
+	if conID == 0 {
+		return fmt.Errorf("mark %q is not set on a window", mark)
+	}
+
+	return s.FocusWindow(strconv.Itoa(conID))
+}
@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+const swayMarkedTree = `{"id": 1, "type": "root", "nodes": [
+	{"id": 2, "type": "output", "name": "eDP-1", "nodes": [
+		{"id": 3, "type": "workspace", "name": "1", "nodes": [
+			{"id": 31, "type": "con", "name": "mail", "app_id": "thunderbird", "marks": ["m", "work"]},
+			{"id": 32, "type": "con", "name": "term", "app_id": "foot", "focused": true, "marks": []}
+		]}
+	]}
+]}`
+
+func TestSwayProvider_Marks(t *testing.T) {
+	fake := newFakeI3(t, map[i3MessageType]func(string) string{
+		i3GetTree:    i3Reply(swayMarkedTree),
+		i3GetMarks:   i3Reply(`["m","work"]`),
+		i3RunCommand: i3Reply(`[{"success":true}]`),
+	})
+	t.Setenv("SWAYSOCK", fake.socketPath)
+
+	provider := &SwayProvider{}
+
+	windows, err := provider.ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	if len(windows) != 2 {
+		t.Fatalf("expected 2 windows, got %d", len(windows))
+	}
+	if got := strings.Join(windows[0].Sway.Marks, ","); got != "m,work" {
+		t.Errorf("marks of first window = %q", got)
+	}
+	if windows[1].Sway.Marks == nil || len(windows[1].Sway.Marks) != 0 {
+		t.Errorf("second window should have no marks, got %v", windows[1].Sway.Marks)
+	}
+	<-fake.requests
+
+	marks, err := provider.ListMarks()
+	if err != nil || strings.Join(marks, ",") != "m,work" {
+		t.Errorf("ListMarks() = %v, %v", marks, err)
+	}
+	<-fake.requests
+
+	if err := provider.AddMark("32", `a "quoted" mark`); err != nil {
+		t.Errorf("AddMark() error: %v", err)
+	}
+	if got := <-fake.requests; got != `[con_id=32] mark --add "a \"quoted\" mark"` {
+		t.Errorf("AddMark() sent %q", got)
+	}
+
+	if err := provider.RemoveMark("31", "work"); err != nil {
+		t.Errorf("RemoveMark() error: %v", err)
+	}
+	if got := <-fake.requests; got != `[con_id=31] unmark "work"` {
+		t.Errorf("RemoveMark() sent %q", got)
+	}
+
+	if err := provider.FocusMark("work"); err != nil {
+		t.Errorf("FocusMark() error: %v", err)
+	}
+	<-fake.requests // GET_MARKS
+	<-fake.requests // GET_TREE
+	if got := <-fake.requests; got != `[con_id=31] focus` {
+		t.Errorf("FocusMark() sent %q", got)
+	}
+
+	if err := provider.FocusMark("missing"); err == nil {
+		t.Error("FocusMark() should fail for an unknown mark")
+	}
+}
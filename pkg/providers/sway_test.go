@@ -0,0 +1,137 @@
+package providers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// swayTestTree is a trimmed GET_TREE reply with two outputs and the scratchpad
+const swayTestTree = `{
+	"id": 1, "type": "root", "name": "root",
+	"nodes": [
+		{"id": 2, "type": "output", "name": "__i3", "nodes": [
+			{"id": 3, "type": "workspace", "name": "__i3_scratch", "nodes": [], "floating_nodes": [
+				{"id": 30, "type": "floating_con", "name": "scratch term", "focused": %SCRATCH%,
+				 "window_properties": {"class": "Alacritty", "title": "scratch term"}, "pid": 300}
+			]}
+		]},
+		{"id": 4, "type": "output", "name": "eDP-1", "nodes": [
+			{"id": 5, "type": "workspace", "name": "1", "nodes": [
+				{"id": 50, "type": "con", "name": "vim", "focused": false, "layout": "none",
+				 "window_properties": {"class": "URxvt", "title": "vim"}, "pid": 500}
+			]}
+		]},
+		{"id": 6, "type": "output", "name": "HDMI-A-1", "nodes": [
+			{"id": 7, "type": "workspace", "name": "2: web", "representation": "H[Firefox]", "nodes": [
+				{"id": 70, "type": "con", "name": null, "layout": "splith", "representation": "H[Firefox]", "nodes": [
+					{"id": 71, "type": "con", "name": "Mozilla Firefox", "focused": %TILED%,
+					 "window_properties": {"class": "Firefox", "title": "Mozilla Firefox"}, "pid": 710}
+				]}
+			], "floating_nodes": [
+				{"id": 72, "type": "floating_con", "name": "Picture-in-Picture", "focused": %FLOATING%,
+				 "window_properties": {"class": "Firefox", "title": "Picture-in-Picture"}, "pid": 710}
+			]}
+		]}
+	]
+}`
+
+func decodeSwayTestTree(t *testing.T, scratch, tiled, floating bool) *swayNode {
+	t.Helper()
+	payload := strings.NewReplacer(
+		"%SCRATCH%", strconv.FormatBool(scratch),
+		"%TILED%", strconv.FormatBool(tiled),
+		"%FLOATING%", strconv.FormatBool(floating),
+	).Replace(swayTestTree)
+
+	var root swayNode
+	if err := json.Unmarshal([]byte(payload), &root); err != nil {
+		t.Fatalf("failed to decode test tree: %v", err)
+	}
+	return &root
+}
+
+func TestFindSwayFocus(t *testing.T) {
+	tests := []struct {
+		name                         string
+		scratch, tiled, floating     bool
+		id, title, workspace, output string
+	}{
+		{"tiled window in nested split", false, true, false, "71", "Mozilla Firefox", "2: web", "HDMI-A-1"},
+		{"floating window", false, false, true, "72", "Picture-in-Picture", "2: web", "HDMI-A-1"},
+		{"scratchpad window", true, false, false, "30", "scratch term", "__i3_scratch", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := decodeSwayTestTree(t, tt.scratch, tt.tiled, tt.floating)
+
+			focus := findSwayFocus(root)
+			if focus == nil {
+				t.Fatal("expected a focused window")
+			}
+
+			info := focus.toWindowInfo()
+			if info.ID != tt.id || info.Title != tt.title || info.Workspace != tt.workspace || info.Monitor != tt.output {
+				t.Errorf("got id=%q title=%q workspace=%q monitor=%q, want %q %q %q %q",
+					info.ID, info.Title, info.Workspace, info.Monitor, tt.id, tt.title, tt.workspace, tt.output)
+			}
+		})
+	}
+}
+
+func TestFindSwayFocus_NoFocusedWindow(t *testing.T) {
+	root := decodeSwayTestTree(t, false, false, false)
+	if focus := findSwayFocus(root); focus != nil {
+		t.Errorf("expected no focused window, got %+v", focus.window)
+	}
+}
+
+func TestSwayNode_ToWindowInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		payload  string
+		expected window.WindowInfo
+	}{
+		{
+			name: "native Wayland window",
+			payload: `{"id": 12, "type": "con", "name": "~/src", "focused": true, "app_id": "foot",
+				"pid": 1234, "shell": "xdg_shell", "inhibit_idle": false}`,
+			expected: window.WindowInfo{
+				ID: "12", Title: "~/src", Class: "foot", AppID: "foot", PID: 1234, Workspace: "1",
+				XWayland: window.Bool(false), Sway: &window.SwayDetails{Shell: "xdg_shell"},
+			},
+		},
+		{
+			name: "XWayland window",
+			payload: `{"id": 13, "type": "con", "name": "Steam", "focused": true, "app_id": null,
+				"pid": 4321, "shell": "xwayland", "inhibit_idle": true,
+				"window_properties": {"class": "steam", "instance": "steamwebhelper", "title": "Steam"}}`,
+			expected: window.WindowInfo{
+				ID: "13", Title: "Steam", Class: "steam", Instance: "steamwebhelper", PID: 4321, Workspace: "1",
+				XWayland: window.Bool(true), Sway: &window.SwayDetails{Shell: "xwayland", InhibitIdle: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var node swayNode
+			if err := json.Unmarshal([]byte(tt.payload), &node); err != nil {
+				t.Fatal(err)
+			}
+			if !node.isView() {
+				t.Error("expected node to be recognized as a window")
+			}
+
+			info := node.toWindowInfo("1")
+			if !reflect.DeepEqual(*info, tt.expected) {
+				t.Errorf("toWindowInfo() = %+v\nwant %+v", *info, tt.expected)
+			}
+		})
+	}
+}
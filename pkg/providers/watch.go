@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// watchEmitter sends watch events, dropping any event identical to the
+// previous one so consumers only hear about visible changes
+type watchEmitter struct {
+	ctx     context.Context
+	out     chan<- window.Event
+	last    window.Event
+	started bool
+}
+
+// newWatchEmitter creates an emitter whose first event will carry mode
+func newWatchEmitter(ctx context.Context, out chan<- window.Event, mode string) *watchEmitter {
+	return &watchEmitter{ctx: ctx, out: out, last: window.Event{Mode: mode}}
+}
+
+// emit sends event unless nothing changed. It returns false once ctx is done.
+func (e *watchEmitter) emit(event window.Event) bool {
+	if e.started && reflect.DeepEqual(e.last, event) {
+		return true
+	}
+	e.last = event
+	e.started = true
+
+	select {
+	case e.out <- event:
+		return true
+	case <-e.ctx.Done():
+		return false
+	}
+}
+
+// setWindow reports a new active window, keeping the current mode
+func (e *watchEmitter) setWindow(info *window.WindowInfo) bool {
+	event := e.last
+	event.Window = info
+	return e.emit(event)
+}
+
+// setMode reports a new binding mode, keeping the current window
+func (e *watchEmitter) setMode(mode string) bool {
+	event := e.last
+	event.Mode = mode
+	return e.emit(event)
+}
@@ -0,0 +1,171 @@
+package providers
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// wlDisplayID is the wl_display object every Wayland connection starts with
+const wlDisplayID = 1
+
+// wlConn speaks the Wayland wire protocol over a unix socket. It only supports
+// what the foreign toplevel protocols need, so no file descriptors.
+type wlConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	// mu serializes writes
+	mu     sync.Mutex
+	nextID uint32
+}
+
+// wlMessage is a request or event; args holds the arguments not decoded yet
+type wlMessage struct {
+	object uint32
+	opcode uint16
+	args   []byte
+}
+
+func newWlConn(conn net.Conn) *wlConn {
+	return &wlConn{conn: conn, reader: bufio.NewReader(conn), nextID: wlDisplayID + 1}
+}
+
+// dialWayland connects to the compositor named by WAYLAND_DISPLAY, which is
+// either a socket path or a name under XDG_RUNTIME_DIR
+func dialWayland() (net.Conn, error) {
+	display := os.Getenv("WAYLAND_DISPLAY")
+	if display == "" {
+		display = "wayland-0"
+	}
+	if !filepath.IsAbs(display) {
+		runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+		if runtimeDir == "" {
+			return nil, fmt.Errorf("XDG_RUNTIME_DIR is not set, so the Wayland socket can't be found")
+		}
+		display = filepath.Join(runtimeDir, display)
+	}
+
+	conn, err := net.Dial("unix", display)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Wayland compositor: %w", err)
+	}
+	return conn, nil
+}
+
+// newID allocates a client object ID
+func (c *wlConn) newID() uint32 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+// send writes a message. Arguments are uint32 (also for object IDs), int32,
+// string or []byte (an array).
+func (c *wlConn) send(object uint32, opcode uint16, args ...any) error {
+	var body []byte
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case uint32:
+			body = binary.NativeEndian.AppendUint32(body, v)
+		case int32:
+			body = binary.NativeEndian.AppendUint32(body, uint32(v))
+		case string:
+			body = wlAppendArray(body, append([]byte(v), 0))
+		case []byte:
+			body = wlAppendArray(body, v)
+		default:
+			return fmt.Errorf("unsupported Wayland argument type %T", arg)
+		}
+	}
+
+	message := make([]byte, 8, 8+len(body))
+	binary.NativeEndian.PutUint32(message, object)
+	binary.NativeEndian.PutUint32(message[4:], uint32(8+len(body))<<16|uint32(opcode))
+	message = append(message, body...)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.conn.Write(message); err != nil {
+		return fmt.Errorf("failed to send Wayland message: %w", err)
+	}
+	return nil
+}
+
+// wlAppendArray appends a length-prefixed array, padded to 32 bits
+func wlAppendArray(buf, data []byte) []byte {
+	buf = binary.NativeEndian.AppendUint32(buf, uint32(len(data)))
+	buf = append(buf, data...)
+	for len(buf)%4 != 0 {
+		buf = append(buf, 0)
+	}
+	return buf
+}
+
+// read blocks until the next message arrives
+func (c *wlConn) read() (*wlMessage, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return nil, err
+	}
+	word := binary.NativeEndian.Uint32(header[4:])
+	size := int(word >> 16)
+	if size < 8 {
+		return nil, fmt.Errorf("invalid Wayland message size %d", size)
+	}
+
+	args := make([]byte, size-8)
+	if _, err := io.ReadFull(c.reader, args); err != nil {
+		return nil, err
+	}
+	return &wlMessage{object: binary.NativeEndian.Uint32(header), opcode: uint16(word), args: args}, nil
+}
+
+// Close closes the connection
+func (c *wlConn) Close() error {
+	return c.conn.Close()
+}
+
+// uint decodes the next uint, int or object argument
+func (m *wlMessage) uint() uint32 {
+	if len(m.args) < 4 {
+		m.args = nil
+		return 0
+	}
+	v := binary.NativeEndian.Uint32(m.args)
+	m.args = m.args[4:]
+	return v
+}
+
+// array decodes the next array argument
+func (m *wlMessage) array() []byte {
+	n := int(m.uint())
+	padded := (n + 3) &^ 3
+	if padded > len(m.args) {
+		m.args = nil
+		return nil
+	}
+	data := m.args[:n]
+	m.args = m.args[padded:]
+	return data
+}
+
+// string decodes the next string argument; a null string decodes as ""
+func (m *wlMessage) string() string {
+	return strings.TrimSuffix(string(m.array()), "\x00")
+}
+
+// uints decodes an array argument of 32-bit values, such as toplevel states
+func (m *wlMessage) uints() []uint32 {
+	data := m.array()
+	values := make([]uint32, 0, len(data)/4)
+	for i := 0; i+4 <= len(data); i += 4 {
+		values = append(values, binary.NativeEndian.Uint32(data[i:]))
+	}
+	return values
+}
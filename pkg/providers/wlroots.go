@@ -0,0 +1,150 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// WlrootsProvider implements window information retrieval for Wayland
+// compositors without their own IPC (river, labwc, Wayfire...) through the
+// foreign toplevel protocols
+type WlrootsProvider struct {
+	// dial connects to the compositor; nil means the socket named by WAYLAND_DISPLAY
+	dial func() (net.Conn, error)
+}
+
+// Name returns the provider name
+func (w *WlrootsProvider) Name() string {
+	return "wlroots"
+}
+
+// GetActiveWindow returns the toplevel the compositor marks as activated
+func (w *WlrootsProvider) GetActiveWindow() (*window.WindowInfo, error) {
+	s, err := w.session()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	if s.manager == 0 {
+		return nil, fmt.Errorf("the compositor only offers %s, which doesn't say which window is active", extToplevelListIface)
+	}
+	info := s.activeWindow()
+	if info == nil {
+		return nil, fmt.Errorf("no active window found")
+	}
+	return info, nil
+}
+
+// ListWindows returns every toplevel
+func (w *WlrootsProvider) ListWindows() ([]*window.WindowInfo, error) {
+	s, err := w.session()
+	if err != nil {
+		return nil, err
+	}
+	defer s.Close()
+
+	windows := make([]*window.WindowInfo, 0, len(s.toplevels))
+	for i := range s.toplevels {
+		windows = append(windows, s.windowInfo(i))
+	}
+	return windows, nil
+}
+
+// Watch reports active window changes as the compositor sends toplevel updates
+func (w *WlrootsProvider) Watch(ctx context.Context) (<-chan window.Event, error) {
+	s, err := w.session()
+	if err != nil {
+		return nil, err
+	}
+	if s.manager == 0 {
+		s.Close()
+		return nil, fmt.Errorf("the compositor only offers %s, which doesn't say which window is active", extToplevelListIface)
+	}
+
+	out := make(chan window.Event)
+	go func() {
+		defer close(out)
+		defer s.Close()
+		// Closing the connection unblocks the read below
+		stop := context.AfterFunc(ctx, func() { s.Close() })
+		defer stop()
+
+		emitter := newWatchEmitter(ctx, out, "")
+		if !emitter.setWindow(s.activeWindow()) {
+			return
+		}
+		for {
+			if err := s.dispatch(); err != nil {
+				return
+			}
+			if !s.changed {
+				continue
+			}
+			s.changed = false
+			s.identify()
+			if !emitter.setWindow(s.activeWindow()) {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// activeWindow returns the activated toplevel, nil when there is none
+func (s *wlSession) activeWindow() *window.WindowInfo {
+	for i, toplevel := range s.toplevels {
+		if toplevel.activated {
+			return s.windowInfo(i)
+		}
+	}
+	return nil
+}
+
+// windowInfo converts the toplevel at index. Its ID is the ext identifier
+// when the compositor offers one; otherwise the wlr protocol gives windows no
+// lasting ID, so it is the 1-based position in the compositor's list and the
+// app_id, which actions check before touching the window.
+func (s *wlSession) windowInfo(index int) *window.WindowInfo {
+	toplevel := s.toplevels[index]
+	id := strconv.Itoa(index + 1)
+	switch {
+	case toplevel.identifier != "":
+		id = toplevel.identifier
+	case toplevel.appID != "":
+		id += ":" + toplevel.appID
+	}
+	info := &window.WindowInfo{
+		ID:         id,
+		Title:      toplevel.title,
+		Class:      toplevel.appID,
+		AppID:      toplevel.appID,
+		Fullscreen: window.Bool(toplevel.fullscreen),
+		Toplevel: &window.ToplevelDetails{
+			Identifier: toplevel.identifier,
+			Maximized:  toplevel.maximized,
+			Minimized:  toplevel.minimized,
+		},
+	}
+	if len(toplevel.outputs) > 0 {
+		info.Monitor = s.outputNames[toplevel.outputs[0]]
+	}
+	return info
+}
+
+// session connects to the compositor and collects its toplevels
+func (w *WlrootsProvider) session() (*wlSession, error) {
+	dial := w.dial
+	if dial == nil {
+		dial = dialWayland
+	}
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	return openWlSession(conn)
+}
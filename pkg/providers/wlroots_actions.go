@@ -0,0 +1,118 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// withToplevel runs action on the toplevel id refers to, then waits for the
+// compositor to process it so protocol errors are reported
+func (w *WlrootsProvider) withToplevel(id string, action func(s *wlSession, toplevel *wlToplevel) error) error {
+	s, err := w.session()
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if s.manager == 0 {
+		return fmt.Errorf("window actions need %s, which the compositor doesn't offer", wlrToplevelManagerIface)
+	}
+	toplevel, err := s.lookup(strings.TrimSpace(id))
+	if err != nil {
+		return err
+	}
+
+	if err := action(s, toplevel); err != nil {
+		return err
+	}
+	return s.roundtrip()
+}
+
+// lookup finds the toplevel with an ID from windowInfo: an ext identifier, or
+// a position and app_id. A window that was closed since the ID was listed
+// isn't mistaken for the one that took its place.
+func (s *wlSession) lookup(id string) (*wlToplevel, error) {
+	for _, toplevel := range s.toplevels {
+		if toplevel.identifier != "" && toplevel.identifier == id {
+			return toplevel, nil
+		}
+	}
+
+	position, appID, checkAppID := strings.Cut(id, ":")
+	index, err := strconv.Atoi(position)
+	if err != nil || index < 1 {
+		if s.list != 0 {
+			return nil, fmt.Errorf("no window %q: it may have closed, see 'yawi list'", id)
+		}
+		return nil, fmt.Errorf("invalid window ID %q: expected a window ID from 'yawi list'", id)
+	}
+	if index > len(s.toplevels) {
+		return nil, fmt.Errorf("no window %d: there are %d windows", index, len(s.toplevels))
+	}
+
+	toplevel := s.toplevels[index-1]
+	if checkAppID && toplevel.appID != appID {
+		return nil, fmt.Errorf("window %d is now %q, not %q: the window list changed, see 'yawi list'", index, toplevel.appID, appID)
+	}
+	return toplevel, nil
+}
+
+// FocusWindow activates the window on the first seat
+func (w *WlrootsProvider) FocusWindow(id string) error {
+	return w.withToplevel(id, func(s *wlSession, toplevel *wlToplevel) error {
+		if s.seat == 0 {
+			return fmt.Errorf("the compositor has no seat to activate the window on")
+		}
+		return s.conn.send(toplevel.handle, wlrHandleActivate, s.seat)
+	})
+}
+
+// CloseWindow asks the window to close
+func (w *WlrootsProvider) CloseWindow(id string) error {
+	return w.withToplevel(id, func(s *wlSession, toplevel *wlToplevel) error {
+		return s.conn.send(toplevel.handle, wlrHandleClose)
+	})
+}
+
+// MinimizeWindow minimizes the window
+func (w *WlrootsProvider) MinimizeWindow(id string) error {
+	return w.withToplevel(id, func(s *wlSession, toplevel *wlToplevel) error {
+		return s.conn.send(toplevel.handle, wlrHandleSetMinimized)
+	})
+}
+
+// ToggleMaximize maximizes the window, or restores it if it is maximized
+func (w *WlrootsProvider) ToggleMaximize(id string) error {
+	return w.withToplevel(id, func(s *wlSession, toplevel *wlToplevel) error {
+		if toplevel.maximized {
+			return s.conn.send(toplevel.handle, wlrHandleUnsetMaximized)
+		}
+		return s.conn.send(toplevel.handle, wlrHandleSetMaximized)
+	})
+}
+
+// ToggleFullscreen switches the window in and out of fullscreen, on the output
+// the compositor picks
+func (w *WlrootsProvider) ToggleFullscreen(id string) error {
+	return w.withToplevel(id, func(s *wlSession, toplevel *wlToplevel) error {
+		if s.managerVersion < wlrFullscreenSince {
+			return fmt.Errorf("the compositor's %s is too old for fullscreen requests", wlrToplevelManagerIface)
+		}
+		if toplevel.fullscreen {
+			return s.conn.send(toplevel.handle, wlrHandleUnsetFullscreen)
+		}
+		// A null output lets the compositor choose
+		return s.conn.send(toplevel.handle, wlrHandleSetFullscreen, uint32(0))
+	})
+}
+
+// ToggleFloating isn't part of the foreign toplevel protocols
+func (w *WlrootsProvider) ToggleFloating(id string) error {
+	return fmt.Errorf("the foreign toplevel protocol has no floating windows; use your compositor's own tools")
+}
+
+// MoveWindowToWorkspace isn't part of the foreign toplevel protocols
+func (w *WlrootsProvider) MoveWindowToWorkspace(id, workspace string) error {
+	return fmt.Errorf("the foreign toplevel protocol doesn't know about workspaces; use your compositor's own tools")
+}
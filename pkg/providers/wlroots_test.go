@@ -0,0 +1,490 @@
+package providers
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alde/yawi/pkg/window"
+)
+
+// fakeToplevel is a window of fakeCompositor
+type fakeToplevel struct {
+	title      string
+	appID      string
+	identifier string
+	states     []uint32
+}
+
+// fakeCompositor is a tiny in-process Wayland server offering the foreign
+// toplevel globals, standing in for a wlroots compositor
+type fakeCompositor struct {
+	path    string
+	globals []wlGlobal
+
+	mu        sync.Mutex
+	toplevels []*fakeToplevel
+	// requests records the toplevel requests received, e.g. "activate foot"
+	requests []string
+	// client is the last connection that bound the wlr manager, and handles
+	// its handle for each toplevel; extClient and extHandles are the same for
+	// the ext list
+	client     *wlConn
+	handles    map[*fakeToplevel]uint32
+	extClient  *wlConn
+	extHandles map[*fakeToplevel]uint32
+}
+
+// fakeGlobalVersions are the versions fakeCompositor advertises
+var fakeGlobalVersions = map[string]uint32{
+	wlSeatIface:             7,
+	wlOutputIface:           4,
+	wlrToplevelManagerIface: 3,
+	extToplevelListIface:    1,
+}
+
+// newFakeCompositor starts a compositor advertising the given globals
+func newFakeCompositor(t *testing.T, toplevels []*fakeToplevel, globals ...string) *fakeCompositor {
+	f := &fakeCompositor{path: filepath.Join(t.TempDir(), "wayland-0"), toplevels: toplevels}
+	for i, iface := range globals {
+		f.globals = append(f.globals, wlGlobal{name: uint32(i + 1), iface: iface, version: fakeGlobalVersions[iface]})
+	}
+
+	listener, err := net.Listen("unix", f.path)
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go f.serve(newWlConn(conn))
+		}
+	}()
+	return f
+}
+
+// provider returns a provider connected to f
+func (f *fakeCompositor) provider() *WlrootsProvider {
+	return &WlrootsProvider{dial: func() (net.Conn, error) { return net.Dial("unix", f.path) }}
+}
+
+// serve answers one client's requests
+func (f *fakeCompositor) serve(c *wlConn) {
+	defer c.Close()
+	objects := map[uint32]string{wlDisplayID: wlDisplayIface}
+	handles := make(map[uint32]*fakeToplevel)
+	nextID := uint32(0xff000000)
+	var output uint32
+
+	for {
+		msg, err := c.read()
+		if err != nil {
+			return
+		}
+
+		switch objects[msg.object] {
+		case wlDisplayIface:
+			id := msg.uint()
+			switch msg.opcode {
+			case wlDisplaySync:
+				c.send(id, wlCallbackDone, uint32(0))
+				c.send(wlDisplayID, wlDisplayDeleteID, id)
+			case wlDisplayGetRegistry:
+				objects[id] = wlRegistryIface
+				for _, global := range f.globals {
+					c.send(id, wlRegistryGlobal, global.name, global.iface, global.version)
+				}
+			}
+
+		case wlRegistryIface:
+			_, iface, _, id := msg.uint(), msg.string(), msg.uint(), msg.uint()
+			objects[id] = iface
+			switch iface {
+			case wlOutputIface:
+				output = id
+				c.send(id, wlOutputName, "DP-1")
+			case wlrToplevelManagerIface, extToplevelListIface:
+				f.mu.Lock()
+				reverse := make(map[*fakeToplevel]uint32)
+				for _, toplevel := range f.toplevels {
+					handle := nextID
+					nextID++
+					handles[handle] = toplevel
+					reverse[toplevel] = handle
+					if iface == wlrToplevelManagerIface {
+						objects[handle] = wlrToplevelHandleIface
+						c.send(id, wlrManagerToplevel, handle)
+						c.send(handle, wlrHandleTitle, toplevel.title)
+						c.send(handle, wlrHandleAppID, toplevel.appID)
+						if output != 0 {
+							c.send(handle, wlrHandleOutputEnter, output)
+						}
+						c.send(handle, wlrHandleState, fakeStates(toplevel.states))
+						c.send(handle, wlrHandleDone)
+					} else {
+						objects[handle] = extToplevelHandleIface
+						c.send(id, extListToplevel, handle)
+						c.send(handle, extHandleIdentifier, toplevel.identifier)
+						c.send(handle, extHandleTitle, toplevel.title)
+						c.send(handle, extHandleAppID, toplevel.appID)
+						c.send(handle, extHandleDone)
+					}
+				}
+				if iface == wlrToplevelManagerIface {
+					f.client, f.handles = c, reverse
+				} else {
+					f.extClient, f.extHandles = c, reverse
+				}
+				f.mu.Unlock()
+			}
+
+		case wlrToplevelHandleIface:
+			f.mu.Lock()
+			toplevel := handles[msg.object]
+			switch msg.opcode {
+			case wlrHandleActivate:
+				f.requests = append(f.requests, "activate "+toplevel.appID)
+				f.activateLocked(toplevel)
+			case wlrHandleClose:
+				f.requests = append(f.requests, "close "+toplevel.appID)
+			case wlrHandleSetMaximized:
+				f.requests = append(f.requests, "maximize "+toplevel.appID)
+			case wlrHandleUnsetMaximized:
+				f.requests = append(f.requests, "unmaximize "+toplevel.appID)
+			case wlrHandleSetMinimized:
+				f.requests = append(f.requests, "minimize "+toplevel.appID)
+			case wlrHandleSetFullscreen:
+				f.requests = append(f.requests, "fullscreen "+toplevel.appID)
+			case wlrHandleUnsetFullscreen:
+				f.requests = append(f.requests, "unfullscreen "+toplevel.appID)
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+// fakeStates encodes toplevel states as a Wayland array
+func fakeStates(states []uint32) []byte {
+	var data []byte
+	for _, state := range states {
+		data = binary.NativeEndian.AppendUint32(data, state)
+	}
+	return data
+}
+
+// activate focuses the toplevel at index, as if the user clicked it
+func (f *fakeCompositor) activate(index int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activateLocked(f.toplevels[index])
+}
+
+// activateLocked moves the activated state to toplevel, telling the client
+func (f *fakeCompositor) activateLocked(toplevel *fakeToplevel) {
+	for _, other := range f.toplevels {
+		activated := other == toplevel
+		var states []uint32
+		for _, state := range other.states {
+			if state != wlrStateActivated {
+				states = append(states, state)
+			}
+		}
+		if activated {
+			states = append(states, wlrStateActivated)
+		}
+		other.states = states
+		if handle, ok := f.handles[other]; ok {
+			f.client.send(handle, wlrHandleState, fakeStates(states))
+			f.client.send(handle, wlrHandleDone)
+		}
+	}
+}
+
+// close closes the toplevel at index
+func (f *fakeCompositor) close(index int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	toplevel := f.toplevels[index]
+	f.toplevels = append(f.toplevels[:index], f.toplevels[index+1:]...)
+	if handle, ok := f.handles[toplevel]; ok {
+		f.client.send(handle, wlrHandleClosed)
+	}
+	if handle, ok := f.extHandles[toplevel]; ok {
+		f.extClient.send(handle, extHandleClosed)
+	}
+}
+
+// fakeDesktop is a terminal and a focused, maximized browser
+func fakeDesktop() []*fakeToplevel {
+	return []*fakeToplevel{
+		{title: "~", appID: "foot", identifier: "a1"},
+		{title: "Mozilla Firefox", appID: "firefox", identifier: "b2", states: []uint32{wlrStateMaximized, wlrStateActivated}},
+	}
+}
+
+// allGlobals are the globals of a typical wlroots compositor
+var allGlobals = []string{wlSeatIface, wlOutputIface, wlrToplevelManagerIface, extToplevelListIface}
+
+func TestWlrootsProvider_GetActiveWindow(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), allGlobals...)
+
+	info, err := f.provider().GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	expected := &window.WindowInfo{
+		ID:         "b2",
+		Title:      "Mozilla Firefox",
+		Class:      "firefox",
+		AppID:      "firefox",
+		Monitor:    "DP-1",
+		Fullscreen: window.Bool(false),
+		Toplevel:   &window.ToplevelDetails{Identifier: "b2", Maximized: true},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetActiveWindow() = %+v\nwant %+v", info, expected)
+	}
+}
+
+func TestWlrootsProvider_ListWindows(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), allGlobals...)
+
+	windows, err := f.provider().ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	var got []string
+	for _, w := range windows {
+		got = append(got, w.ID+" "+w.AppID)
+	}
+	if !reflect.DeepEqual(got, []string{"a1 foot", "b2 firefox"}) {
+		t.Errorf("ListWindows() = %q", got)
+	}
+}
+
+func TestWlrootsProvider_ListWindowsWithoutIdentifiers(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), wlSeatIface, wlOutputIface, wlrToplevelManagerIface)
+
+	windows, err := f.provider().ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	var got []string
+	for _, w := range windows {
+		got = append(got, w.ID)
+	}
+	if !reflect.DeepEqual(got, []string{"1:foot", "2:firefox"}) {
+		t.Errorf("ListWindows() IDs = %q", got)
+	}
+}
+
+func TestWlrootsProvider_ExtListOnly(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), extToplevelListIface)
+	provider := f.provider()
+
+	windows, err := provider.ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	if len(windows) != 2 || windows[0].Toplevel.Identifier != "a1" || windows[1].Title != "Mozilla Firefox" {
+		t.Errorf("ListWindows() = %+v", windows)
+	}
+
+	if _, err := provider.GetActiveWindow(); err == nil || !strings.Contains(err.Error(), extToplevelListIface) {
+		t.Errorf("GetActiveWindow() error = %v, want the ext list's limits explained", err)
+	}
+	if err := provider.CloseWindow("1"); err == nil {
+		t.Error("expected actions to fail without the wlr manager")
+	}
+}
+
+func TestWlrootsProvider_NoToplevelProtocol(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), wlSeatIface, wlOutputIface)
+	_, err := f.provider().GetActiveWindow()
+	if err == nil || !strings.Contains(err.Error(), wlrToplevelManagerIface) || !strings.Contains(err.Error(), extToplevelListIface) {
+		t.Errorf("GetActiveWindow() error = %v, want both protocols mentioned", err)
+	}
+}
+
+func TestWlrootsProvider_Watch(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), allGlobals...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := f.provider().Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	next := func() string {
+		select {
+		case event := <-events:
+			if event.Window == nil {
+				return "<none>"
+			}
+			return event.Window.AppID
+		case <-ctx.Done():
+			return "<timeout>"
+		}
+	}
+
+	if got := next(); got != "firefox" {
+		t.Fatalf("first event = %s, want firefox", got)
+	}
+
+	f.activate(0)
+	if got := next(); got != "foot" {
+		t.Errorf("after activating foot got %s", got)
+	}
+
+	// Closing the focused window leaves nothing activated
+	f.close(0)
+	if got := next(); got != "<none>" {
+		t.Errorf("after closing foot got %s", got)
+	}
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWlrootsProvider_Actions(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(w *WlrootsProvider) error
+		want   string
+	}{
+		{"focus", func(w *WlrootsProvider) error { return w.FocusWindow("a1") }, "activate foot"},
+		{"close", func(w *WlrootsProvider) error { return w.CloseWindow("b2") }, "close firefox"},
+		{"minimize", func(w *WlrootsProvider) error { return w.MinimizeWindow("a1") }, "minimize foot"},
+		{"maximize", func(w *WlrootsProvider) error { return w.ToggleMaximize("1:foot") }, "maximize foot"},
+		{"restore", func(w *WlrootsProvider) error { return w.ToggleMaximize("2") }, "unmaximize firefox"},
+		{"fullscreen", func(w *WlrootsProvider) error { return w.ToggleFullscreen("b2") }, "fullscreen firefox"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeCompositor(t, fakeDesktop(), allGlobals...)
+			if err := tt.action(f.provider()); err != nil {
+				t.Fatalf("action error: %v", err)
+			}
+
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			if !reflect.DeepEqual(f.requests, []string{tt.want}) {
+				t.Errorf("requests = %q, want %q", f.requests, tt.want)
+			}
+		})
+	}
+}
+
+func TestWlrootsProvider_ActionErrors(t *testing.T) {
+	f := newFakeCompositor(t, fakeDesktop(), allGlobals...)
+	provider := f.provider()
+
+	for _, id := range []string{"0", "firefox", "3", "2:foot"} {
+		if err := provider.CloseWindow(id); err == nil {
+			t.Errorf("CloseWindow(%q) succeeded, want an error", id)
+		}
+	}
+	if err := provider.MoveWindowToWorkspace("1", "2"); err == nil {
+		t.Error("expected workspace moves to be unsupported")
+	}
+
+	seatless := newFakeCompositor(t, fakeDesktop(), wlOutputIface, wlrToplevelManagerIface)
+	if err := seatless.provider().FocusWindow("1"); err == nil || !strings.Contains(err.Error(), "seat") {
+		t.Errorf("FocusWindow() error = %v, want the missing seat mentioned", err)
+	}
+}
+
+func TestWlrootsProvider_ActionAfterWindowClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		globals []string
+		// stable is whether firefox's ID still finds it once foot is gone
+		stable bool
+	}{
+		{"identifiers", allGlobals, true},
+		{"positions", []string{wlSeatIface, wlOutputIface, wlrToplevelManagerIface}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeCompositor(t, fakeDesktop(), tt.globals...)
+			provider := f.provider()
+
+			windows, err := provider.ListWindows()
+			if err != nil {
+				t.Fatalf("ListWindows() error: %v", err)
+			}
+			foot, firefox := windows[0].ID, windows[1].ID
+
+			// foot closes before the user acts on what they listed, so
+			// firefox moves into its place
+			f.close(0)
+			if err := provider.CloseWindow(foot); err == nil {
+				t.Errorf("CloseWindow(%q) succeeded after foot closed", foot)
+			}
+			f.mu.Lock()
+			requests := f.requests
+			f.mu.Unlock()
+			if len(requests) != 0 {
+				t.Fatalf("requests = %q, want firefox left alone", requests)
+			}
+
+			if tt.stable {
+				if err := provider.CloseWindow(firefox); err != nil {
+					t.Fatalf("CloseWindow(%q) error: %v", firefox, err)
+				}
+				f.mu.Lock()
+				defer f.mu.Unlock()
+				if !reflect.DeepEqual(f.requests, []string{"close firefox"}) {
+					t.Errorf("requests = %q, want firefox closed", f.requests)
+				}
+			}
+		})
+	}
+}
+
+func TestWlrootsProvider_AmbiguousIdentifiers(t *testing.T) {
+	// Two terminals the protocols describe identically
+	toplevels := append([]*fakeToplevel{{title: "~", appID: "foot", identifier: "c3"}}, fakeDesktop()...)
+	f := newFakeCompositor(t, toplevels, allGlobals...)
+	provider := f.provider()
+
+	windows, err := provider.ListWindows()
+	if err != nil {
+		t.Fatalf("ListWindows() error: %v", err)
+	}
+	var got []string
+	for _, w := range windows {
+		got = append(got, w.ID+" "+w.Toplevel.Identifier)
+	}
+	if !reflect.DeepEqual(got, []string{"1:foot ", "2:foot ", "b2 b2"}) {
+		t.Errorf("ListWindows() = %q, want the terminals without identifiers", got)
+	}
+
+	for _, id := range []string{"a1", "c3"} {
+		if err := provider.CloseWindow(id); err == nil {
+			t.Errorf("CloseWindow(%q) succeeded, but which terminal it is can't be known", id)
+		}
+	}
+	if err := provider.CloseWindow("2:foot"); err != nil {
+		t.Fatalf("CloseWindow(2:foot) error: %v", err)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !reflect.DeepEqual(f.requests, []string{"close foot"}) {
+		t.Errorf("requests = %q", f.requests)
+	}
+}
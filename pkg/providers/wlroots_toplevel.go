@@ -0,0 +1,392 @@
+package providers
+
+import (
+	"fmt"
+	"net"
+)
+
+// Wayland interfaces yawi binds or is handed
+const (
+	wlDisplayIface          = "wl_display"
+	wlRegistryIface         = "wl_registry"
+	wlCallbackIface         = "wl_callback"
+	wlSeatIface             = "wl_seat"
+	wlOutputIface           = "wl_output"
+	wlrToplevelManagerIface = "zwlr_foreign_toplevel_manager_v1"
+	wlrToplevelHandleIface  = "zwlr_foreign_toplevel_handle_v1"
+	extToplevelListIface    = "ext_foreign_toplevel_list_v1"
+	extToplevelHandleIface  = "ext_foreign_toplevel_handle_v1"
+)
+
+// Highest versions yawi understands
+const (
+	wlSeatVersion             = 1
+	wlOutputVersion           = 4
+	wlrToplevelManagerVersion = 3
+	extToplevelListVersion    = 1
+)
+
+// wlrFullscreenSince is the first wlr manager version with fullscreen requests
+const wlrFullscreenSince = 2
+
+// Request opcodes
+const (
+	wlDisplaySync        = 0
+	wlDisplayGetRegistry = 1
+	wlRegistryBind       = 0
+
+	wlrHandleSetMaximized    = 0
+	wlrHandleUnsetMaximized  = 1
+	wlrHandleSetMinimized    = 2
+	wlrHandleActivate        = 4
+	wlrHandleClose           = 5
+	wlrHandleDestroy         = 7
+	wlrHandleSetFullscreen   = 8
+	wlrHandleUnsetFullscreen = 9
+
+	extHandleDestroy = 0
+)
+
+// Event opcodes
+const (
+	wlDisplayError    = 0
+	wlDisplayDeleteID = 1
+	wlRegistryGlobal  = 0
+	wlCallbackDone    = 0
+	wlOutputName      = 4
+
+	wlrManagerToplevel   = 0
+	wlrHandleTitle       = 0
+	wlrHandleAppID       = 1
+	wlrHandleOutputEnter = 2
+	wlrHandleOutputLeave = 3
+	wlrHandleState       = 4
+	wlrHandleDone        = 5
+	wlrHandleClosed      = 6
+
+	extListToplevel     = 0
+	extHandleClosed     = 0
+	extHandleDone       = 1
+	extHandleTitle      = 2
+	extHandleAppID      = 3
+	extHandleIdentifier = 4
+)
+
+// zwlr_foreign_toplevel_handle_v1 states
+const (
+	wlrStateMaximized  = 0
+	wlrStateMinimized  = 1
+	wlrStateActivated  = 2
+	wlrStateFullscreen = 3
+)
+
+// wlGlobal is an object the compositor advertises through the registry
+type wlGlobal struct {
+	name    uint32
+	iface   string
+	version uint32
+}
+
+// wlToplevel is a window as the foreign toplevel protocols describe it
+type wlToplevel struct {
+	handle     uint32
+	title      string
+	appID      string
+	identifier string
+	outputs    []uint32
+	maximized  bool
+	minimized  bool
+	activated  bool
+	fullscreen bool
+}
+
+// wlSession is a connection that has bound the foreign toplevel globals and
+// tracks the toplevels they announce
+type wlSession struct {
+	conn    *wlConn
+	objects map[uint32]string
+	globals []wlGlobal
+
+	// manager is the wlr manager, list the ext list; either may be 0
+	manager        uint32
+	managerVersion uint32
+	list           uint32
+	seat           uint32
+
+	outputNames map[uint32]string
+	// toplevels are in announcement order; closed ones are dropped. They come
+	// from the wlr manager when it is bound, otherwise from the ext list.
+	toplevels []*wlToplevel
+	// extToplevels are the ext list's toplevels when the wlr manager is bound
+	// too; they only lend their identifiers to toplevels
+	extToplevels []*wlToplevel
+	handles      map[uint32]*wlToplevel
+	// changed is set when a toplevel finished updating or closed
+	changed bool
+}
+
+// openWlSession binds the wlr toplevel manager and the ext toplevel list,
+// whichever are offered, a seat for activation and the outputs, then waits for
+// the current toplevels to be announced
+func openWlSession(conn net.Conn) (*wlSession, error) {
+	s := &wlSession{
+		conn:        newWlConn(conn),
+		objects:     map[uint32]string{wlDisplayID: wlDisplayIface},
+		outputNames: make(map[uint32]string),
+		handles:     make(map[uint32]*wlToplevel),
+	}
+
+	registry := s.conn.newID()
+	s.objects[registry] = wlRegistryIface
+	if err := s.conn.send(wlDisplayID, wlDisplayGetRegistry, registry); err != nil {
+		s.Close()
+		return nil, err
+	}
+	if err := s.roundtrip(); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	for _, global := range s.globals {
+		var err error
+		switch {
+		case global.iface == wlrToplevelManagerIface && s.manager == 0:
+			s.managerVersion = min(global.version, wlrToplevelManagerVersion)
+			s.manager, err = s.bind(registry, global, s.managerVersion)
+		case global.iface == extToplevelListIface && s.list == 0:
+			s.list, err = s.bind(registry, global, extToplevelListVersion)
+		case global.iface == wlSeatIface && s.seat == 0:
+			s.seat, err = s.bind(registry, global, wlSeatVersion)
+		case global.iface == wlOutputIface:
+			_, err = s.bind(registry, global, min(global.version, wlOutputVersion))
+		}
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	if s.manager == 0 && s.list == 0 {
+		s.Close()
+		return nil, fmt.Errorf("the compositor offers neither %s nor %s, so its windows can't be seen", wlrToplevelManagerIface, extToplevelListIface)
+	}
+
+	if err := s.roundtrip(); err != nil {
+		s.Close()
+		return nil, err
+	}
+	s.identify()
+	s.changed = false
+	return s, nil
+}
+
+// bind creates an object for a global
+func (s *wlSession) bind(registry uint32, global wlGlobal, version uint32) (uint32, error) {
+	id := s.conn.newID()
+	s.objects[id] = global.iface
+	if err := s.conn.send(registry, wlRegistryBind, global.name, global.iface, version, id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// roundtrip handles events until the compositor has processed every request sent so far
+func (s *wlSession) roundtrip() error {
+	callback := s.conn.newID()
+	s.objects[callback] = wlCallbackIface
+	if err := s.conn.send(wlDisplayID, wlDisplaySync, callback); err != nil {
+		return err
+	}
+
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			return fmt.Errorf("failed to read from Wayland compositor: %w", err)
+		}
+		if msg.object == callback && msg.opcode == wlCallbackDone {
+			delete(s.objects, callback)
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// dispatch handles the next event
+func (s *wlSession) dispatch() error {
+	msg, err := s.conn.read()
+	if err != nil {
+		return fmt.Errorf("failed to read from Wayland compositor: %w", err)
+	}
+	return s.handle(msg)
+}
+
+// handle applies an event to the session state
+func (s *wlSession) handle(msg *wlMessage) error {
+	switch s.objects[msg.object] {
+	case wlDisplayIface:
+		switch msg.opcode {
+		case wlDisplayError:
+			object, code, message := msg.uint(), msg.uint(), msg.string()
+			return fmt.Errorf("Wayland protocol error on %s (code %d): %s", s.objects[object], code, message)
+		case wlDisplayDeleteID:
+			delete(s.objects, msg.uint())
+		}
+
+	case wlRegistryIface:
+		if msg.opcode == wlRegistryGlobal {
+			name, iface, version := msg.uint(), msg.string(), msg.uint()
+			s.globals = append(s.globals, wlGlobal{name: name, iface: iface, version: version})
+		}
+
+	case wlOutputIface:
+		if msg.opcode == wlOutputName {
+			s.outputNames[msg.object] = msg.string()
+		}
+
+	case wlrToplevelManagerIface:
+		if msg.opcode == wlrManagerToplevel {
+			s.addToplevel(msg.uint(), wlrToplevelHandleIface)
+		}
+
+	case extToplevelListIface:
+		if msg.opcode == extListToplevel {
+			s.addToplevel(msg.uint(), extToplevelHandleIface)
+		}
+
+	case wlrToplevelHandleIface:
+		return s.handleWlrToplevel(msg)
+
+	case extToplevelHandleIface:
+		return s.handleExtToplevel(msg)
+	}
+	return nil
+}
+
+// addToplevel starts tracking a newly announced toplevel
+func (s *wlSession) addToplevel(handle uint32, iface string) {
+	s.objects[handle] = iface
+	toplevel := &wlToplevel{handle: handle}
+	s.handles[handle] = toplevel
+	if iface == extToplevelHandleIface && s.manager != 0 {
+		s.extToplevels = append(s.extToplevels, toplevel)
+		return
+	}
+	s.toplevels = append(s.toplevels, toplevel)
+}
+
+// removeToplevel forgets a closed toplevel and destroys its handle
+func (s *wlSession) removeToplevel(handle uint32, destroy uint16) error {
+	s.toplevels = wlWithoutHandle(s.toplevels, handle)
+	s.extToplevels = wlWithoutHandle(s.extToplevels, handle)
+	delete(s.handles, handle)
+	s.changed = true
+	return s.conn.send(handle, destroy)
+}
+
+// wlWithoutHandle drops the toplevel with handle from toplevels
+func wlWithoutHandle(toplevels []*wlToplevel, handle uint32) []*wlToplevel {
+	for i, toplevel := range toplevels {
+		if toplevel.handle == handle {
+			return append(toplevels[:i], toplevels[i+1:]...)
+		}
+	}
+	return toplevels
+}
+
+// identify gives the wlr toplevels the identifiers of the matching ext
+// toplevels. The protocols don't link their handles, so windows are matched by
+// app_id and title; windows sharing both can't be told apart and get none.
+func (s *wlSession) identify() {
+	if s.manager == 0 || s.list == 0 {
+		return
+	}
+	type key struct{ appID, title string }
+	wlr := make(map[key]int)
+	for _, toplevel := range s.toplevels {
+		wlr[key{toplevel.appID, toplevel.title}]++
+	}
+	ext := make(map[key][]*wlToplevel)
+	for _, toplevel := range s.extToplevels {
+		k := key{toplevel.appID, toplevel.title}
+		ext[k] = append(ext[k], toplevel)
+	}
+
+	for _, toplevel := range s.toplevels {
+		toplevel.identifier = ""
+		k := key{toplevel.appID, toplevel.title}
+		if matches := ext[k]; wlr[k] == 1 && len(matches) == 1 {
+			toplevel.identifier = matches[0].identifier
+		}
+	}
+}
+
+func (s *wlSession) handleWlrToplevel(msg *wlMessage) error {
+	toplevel := s.handles[msg.object]
+	if toplevel == nil {
+		return nil
+	}
+
+	switch msg.opcode {
+	case wlrHandleTitle:
+		toplevel.title = msg.string()
+	case wlrHandleAppID:
+		toplevel.appID = msg.string()
+	case wlrHandleOutputEnter:
+		toplevel.outputs = append(toplevel.outputs, msg.uint())
+	case wlrHandleOutputLeave:
+		output := msg.uint()
+		for i, o := range toplevel.outputs {
+			if o == output {
+				toplevel.outputs = append(toplevel.outputs[:i], toplevel.outputs[i+1:]...)
+				break
+			}
+		}
+	case wlrHandleState:
+		toplevel.maximized, toplevel.minimized, toplevel.activated, toplevel.fullscreen = false, false, false, false
+		for _, state := range msg.uints() {
+			switch state {
+			case wlrStateMaximized:
+				toplevel.maximized = true
+			case wlrStateMinimized:
+				toplevel.minimized = true
+			case wlrStateActivated:
+				toplevel.activated = true
+			case wlrStateFullscreen:
+				toplevel.fullscreen = true
+			}
+		}
+	case wlrHandleDone:
+		s.changed = true
+	case wlrHandleClosed:
+		return s.removeToplevel(msg.object, wlrHandleDestroy)
+	}
+	return nil
+}
+
+func (s *wlSession) handleExtToplevel(msg *wlMessage) error {
+	toplevel := s.handles[msg.object]
+	if toplevel == nil {
+		return nil
+	}
+
+	switch msg.opcode {
+	case extHandleTitle:
+		toplevel.title = msg.string()
+	case extHandleAppID:
+		toplevel.appID = msg.string()
+	case extHandleIdentifier:
+		toplevel.identifier = msg.string()
+	case extHandleDone:
+		s.changed = true
+	case extHandleClosed:
+		return s.removeToplevel(msg.object, extHandleDestroy)
+	}
+	return nil
+}
+
+// Close disconnects from the compositor, which cleans up every object
+func (s *wlSession) Close() error {
+	return s.conn.Close()
+}
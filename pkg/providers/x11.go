@@ -0,0 +1,172 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11AllDesktops is the _NET_WM_DESKTOP value of windows shown on every desktop
+const x11AllDesktops = 0xFFFFFFFF
+
+// X11Provider implements window information retrieval for X11 window managers
+// that follow the EWMH spec (dwm with the ewmh patch, awesome, Openbox, XFCE...)
+type X11Provider struct {
+	// conn opens the X connection; nil means the server named by DISPLAY
+	conn func() (x11Conn, error)
+}
+
+// Name returns the provider name
+func (x *X11Provider) Name() string {
+	return "X11"
+}
+
+// GetActiveWindow retrieves the window named by the root window's _NET_ACTIVE_WINDOW
+func (x *X11Provider) GetActiveWindow() (*window.WindowInfo, error) {
+	conn, err := x.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	active, err := x11ActiveWindow(conn)
+	if err != nil {
+		return nil, err
+	}
+	if active == 0 {
+		return nil, fmt.Errorf("no active window found")
+	}
+
+	return x11WindowInfo(conn, active)
+}
+
+// ListWindows returns every window the window manager manages, from
+// _NET_CLIENT_LIST or, if only the stacking order is kept, _NET_CLIENT_LIST_STACKING
+func (x *X11Provider) ListWindows() ([]*window.WindowInfo, error) {
+	conn, err := x.connect()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var clients []uint32
+	for _, name := range []string{"_NET_CLIENT_LIST", "_NET_CLIENT_LIST_STACKING"} {
+		prop, err := conn.property(conn.root(), name)
+		if err != nil {
+			return nil, err
+		}
+		if prop != nil {
+			clients = prop.cardinals()
+			break
+		}
+	}
+	if clients == nil {
+		return nil, fmt.Errorf("the window manager doesn't set _NET_CLIENT_LIST; yawi needs an EWMH-compliant window manager")
+	}
+
+	windows := make([]*window.WindowInfo, 0, len(clients))
+	for _, client := range clients {
+		info, err := x11WindowInfo(conn, xproto.Window(client))
+		if err != nil {
+			// The window was closed while listing
+			continue
+		}
+		windows = append(windows, info)
+	}
+	return windows, nil
+}
+
+// x11ActiveWindow reads the root window's _NET_ACTIVE_WINDOW, which is 0
+// when nothing has focus
+func x11ActiveWindow(conn x11Conn) (xproto.Window, error) {
+	prop, err := conn.property(conn.root(), "_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return 0, err
+	}
+	if prop == nil {
+		return 0, fmt.Errorf("the window manager doesn't set _NET_ACTIVE_WINDOW; yawi needs an EWMH-compliant window manager")
+	}
+	active, _ := prop.cardinal()
+	return xproto.Window(active), nil
+}
+
+// x11WindowInfo reads a window's EWMH and ICCCM properties
+func x11WindowInfo(conn x11Conn, win xproto.Window) (*window.WindowInfo, error) {
+	info := &window.WindowInfo{
+		ID:  fmt.Sprintf("0x%x", uint32(win)),
+		X11: &window.X11Details{WindowID: uint32(win)},
+	}
+
+	// _NET_WM_NAME is UTF-8; WM_NAME is what older clients set
+	for _, name := range []string{"_NET_WM_NAME", "WM_NAME"} {
+		prop, err := conn.property(win, name)
+		if err != nil {
+			return nil, err
+		}
+		if prop != nil && prop.format == 8 {
+			info.Title = string(prop.value)
+			break
+		}
+	}
+
+	// WM_CLASS holds the instance name followed by the class name
+	prop, err := conn.property(win, "WM_CLASS")
+	if err != nil {
+		return nil, err
+	}
+	if class := prop.strings(); len(class) > 0 {
+		info.Instance = class[0]
+		if len(class) > 1 {
+			info.Class = class[1]
+		}
+	}
+
+	prop, err = conn.property(win, "_NET_WM_PID")
+	if err != nil {
+		return nil, err
+	}
+	if pid, ok := prop.cardinal(); ok {
+		info.PID = int(pid)
+	}
+
+	prop, err = conn.property(win, "_NET_WM_DESKTOP")
+	if err != nil {
+		return nil, err
+	}
+	if desktop, ok := prop.cardinal(); ok {
+		info.Pinned = window.Bool(desktop == x11AllDesktops)
+		if desktop != x11AllDesktops {
+			index := int(desktop)
+			info.X11.DesktopIndex = &index
+			info.Workspace, err = x11DesktopName(conn, index)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// x11DesktopName names a desktop from the root window's _NET_DESKTOP_NAMES,
+// numbering it from 1 when it has no name
+func x11DesktopName(conn x11Conn, index int) (string, error) {
+	prop, err := conn.property(conn.root(), "_NET_DESKTOP_NAMES")
+	if err != nil {
+		return "", err
+	}
+	if names := prop.strings(); index < len(names) && names[index] != "" {
+		return names[index], nil
+	}
+	return strconv.Itoa(index + 1), nil
+}
+
+// connect opens the X connection
+func (x *X11Provider) connect() (x11Conn, error) {
+	if x.conn != nil {
+		return x.conn()
+	}
+	return newX11Conn()
+}
@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11SourcePager tells the window manager a request comes from a pager or
+// similar tool acting for the user, so it isn't treated as focus stealing
+const x11SourcePager = 2
+
+// x11StateToggle is the _NET_WM_STATE action that flips a state
+const x11StateToggle = 2
+
+// x11IconicState is ICCCM's WM_CHANGE_STATE argument for minimizing
+const x11IconicState = 3
+
+// x11WindowID parses a window ID as reported in WindowInfo.ID, in hex or decimal
+func x11WindowID(id string) (xproto.Window, error) {
+	win, err := strconv.ParseUint(strings.TrimSpace(id), 0, 32)
+	if err != nil || win == 0 {
+		return 0, fmt.Errorf("invalid window ID %q: expected an X window ID such as 0x1a00007", id)
+	}
+	return xproto.Window(win), nil
+}
+
+// withWindow runs action with a connection and the window id refers to
+func (x *X11Provider) withWindow(id string, action func(conn x11Conn, win xproto.Window) error) error {
+	win, err := x11WindowID(id)
+	if err != nil {
+		return err
+	}
+
+	conn, err := x.connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return action(conn, win)
+}
+
+// clientMessage sends an EWMH request about the window id refers to
+func (x *X11Provider) clientMessage(id, typ string, data ...uint32) error {
+	return x.withWindow(id, func(conn x11Conn, win xproto.Window) error {
+		return conn.sendClientMessage(win, typ, data...)
+	})
+}
+
+// toggleState flips up to two _NET_WM_STATE states of the window
+func (x *X11Provider) toggleState(id string, states ...string) error {
+	return x.withWindow(id, func(conn x11Conn, win xproto.Window) error {
+		data := []uint32{x11StateToggle, 0, 0, x11SourcePager}
+		for i, state := range states {
+			atom, err := conn.atom(state)
+			if err != nil {
+				return err
+			}
+			data[i+1] = uint32(atom)
+		}
+		return conn.sendClientMessage(win, "_NET_WM_STATE", data...)
+	})
+}
+
+// FocusWindow activates the window; window managers switch to its desktop
+func (x *X11Provider) FocusWindow(id string) error {
+	return x.clientMessage(id, "_NET_ACTIVE_WINDOW", x11SourcePager, xproto.TimeCurrentTime)
+}
+
+// CloseWindow asks the window manager to close the window
+func (x *X11Provider) CloseWindow(id string) error {
+	return x.clientMessage(id, "_NET_CLOSE_WINDOW", xproto.TimeCurrentTime, x11SourcePager)
+}
+
+// MinimizeWindow iconifies the window through ICCCM, as EWMH has no request for it
+func (x *X11Provider) MinimizeWindow(id string) error {
+	return x.clientMessage(id, "WM_CHANGE_STATE", x11IconicState)
+}
+
+// ToggleMaximize maximizes the window in both directions, or restores it
+func (x *X11Provider) ToggleMaximize(id string) error {
+	return x.toggleState(id, "_NET_WM_STATE_MAXIMIZED_VERT", "_NET_WM_STATE_MAXIMIZED_HORZ")
+}
+
+// ToggleFullscreen switches the window in and out of fullscreen
+func (x *X11Provider) ToggleFullscreen(id string) error {
+	return x.toggleState(id, "_NET_WM_STATE_FULLSCREEN")
+}
+
+// TogglePin shows the window on every desktop, or just its own again
+func (x *X11Provider) TogglePin(id string) error {
+	return x.toggleState(id, "_NET_WM_STATE_STICKY")
+}
+
+// ToggleFloating isn't part of EWMH; tiling window managers each do it their own way
+func (x *X11Provider) ToggleFloating(id string) error {
+	return fmt.Errorf("EWMH has no request for floating windows; use your window manager's own tools")
+}
+
+// MoveWindowToWorkspace sends the window to a desktop given by number
+// (counting from 1) or name, then switches there and focuses it
+func (x *X11Provider) MoveWindowToWorkspace(id, workspace string) error {
+	return x.withWindow(id, func(conn x11Conn, win xproto.Window) error {
+		desktop, err := x11DesktopIndex(conn, workspace)
+		if err != nil {
+			return err
+		}
+		if err := conn.sendClientMessage(win, "_NET_WM_DESKTOP", desktop, x11SourcePager); err != nil {
+			return err
+		}
+		if err := conn.sendClientMessage(conn.root(), "_NET_CURRENT_DESKTOP", desktop, xproto.TimeCurrentTime); err != nil {
+			return err
+		}
+		return conn.sendClientMessage(win, "_NET_ACTIVE_WINDOW", x11SourcePager, xproto.TimeCurrentTime)
+	})
+}
+
+// MoveWindowToWorkspaceSilent sends the window to a desktop without following it
+func (x *X11Provider) MoveWindowToWorkspaceSilent(id, workspace string) error {
+	return x.withWindow(id, func(conn x11Conn, win xproto.Window) error {
+		desktop, err := x11DesktopIndex(conn, workspace)
+		if err != nil {
+			return err
+		}
+		return conn.sendClientMessage(win, "_NET_WM_DESKTOP", desktop, x11SourcePager)
+	})
+}
+
+// x11DesktopIndex resolves a desktop name or 1-based number to EWMH's zero-based index
+func x11DesktopIndex(conn x11Conn, workspace string) (uint32, error) {
+	prop, err := conn.property(conn.root(), "_NET_DESKTOP_NAMES")
+	if err != nil {
+		return 0, err
+	}
+	for i, name := range prop.strings() {
+		if name == workspace {
+			return uint32(i), nil
+		}
+	}
+
+	number, err := strconv.Atoi(workspace)
+	if err != nil || number < 1 {
+		return 0, fmt.Errorf("unknown workspace %q: expected a number from 1 or a desktop name", workspace)
+	}
+
+	prop, err = conn.property(conn.root(), "_NET_NUMBER_OF_DESKTOPS")
+	if err != nil {
+		return 0, err
+	}
+	if count, ok := prop.cardinal(); ok && uint32(number) > count {
+		return 0, fmt.Errorf("unknown workspace %q: there are only %d desktops", workspace, count)
+	}
+	return uint32(number - 1), nil
+}
@@ -0,0 +1,196 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11MaxPropertyLength is the longest property read, in 32-bit units
+const x11MaxPropertyLength = 1 << 20
+
+// x11Conn is the part of the X connection the X11 provider needs, so tests can
+// stand in for the X server
+type x11Conn interface {
+	// root returns the default screen's root window, where EWMH state lives
+	root() xproto.Window
+	// property reads a window property by name; nil when it isn't set
+	property(win xproto.Window, name string) (*x11Property, error)
+	// atom returns the atom for name, creating it if needed
+	atom(name string) (xproto.Atom, error)
+	// watchProperties subscribes to PropertyNotify events for win
+	watchProperties(win xproto.Window) error
+	// propertyEvents delivers the subscribed PropertyNotify events; it is
+	// closed along with the connection
+	propertyEvents() <-chan x11PropertyEvent
+	// sendClientMessage sends an EWMH request about win to the window manager
+	sendClientMessage(win xproto.Window, typ string, data ...uint32) error
+	Close()
+}
+
+// x11PropertyEvent reports that a window property changed
+type x11PropertyEvent struct {
+	window xproto.Window
+	atom   xproto.Atom
+}
+
+// x11Property is a raw window property
+type x11Property struct {
+	// format is the item size in bits: 8, 16 or 32
+	format byte
+	value  []byte
+}
+
+// cardinals decodes a 32-bit property such as CARDINAL or WINDOW
+func (p *x11Property) cardinals() []uint32 {
+	if p == nil || p.format != 32 {
+		return nil
+	}
+	values := make([]uint32, 0, len(p.value)/4)
+	for i := 0; i+4 <= len(p.value); i += 4 {
+		values = append(values, xgb.Get32(p.value[i:]))
+	}
+	return values
+}
+
+// cardinal returns the first item of a 32-bit property
+func (p *x11Property) cardinal() (uint32, bool) {
+	values := p.cardinals()
+	if len(values) == 0 {
+		return 0, false
+	}
+	return values[0], true
+}
+
+// strings decodes a list of NUL-terminated strings such as WM_CLASS
+func (p *x11Property) strings() []string {
+	if p == nil || p.format != 8 || len(p.value) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(p.value), "\x00"), "\x00")
+}
+
+// xgbConn talks to the X server named by DISPLAY
+type xgbConn struct {
+	conn       *xgb.Conn
+	rootWindow xproto.Window
+	atoms      map[string]xproto.Atom
+
+	eventsOnce sync.Once
+	events     chan x11PropertyEvent
+	done       chan struct{}
+}
+
+// newX11Conn connects to the X server named by DISPLAY
+func newX11Conn() (*xgbConn, error) {
+	conn, err := xgb.NewConn()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to X server: %w", err)
+	}
+	return &xgbConn{
+		conn:       conn,
+		rootWindow: xproto.Setup(conn).DefaultScreen(conn).Root,
+		atoms:      make(map[string]xproto.Atom),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func (c *xgbConn) root() xproto.Window {
+	return c.rootWindow
+}
+
+// atom looks up (and caches) the atom for name. It isn't safe for concurrent use.
+func (c *xgbConn) atom(name string) (xproto.Atom, error) {
+	if atom, ok := c.atoms[name]; ok {
+		return atom, nil
+	}
+	reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		return 0, fmt.Errorf("failed to intern atom %s: %w", name, err)
+	}
+	c.atoms[name] = reply.Atom
+	return reply.Atom, nil
+}
+
+func (c *xgbConn) property(win xproto.Window, name string) (*x11Property, error) {
+	atom, err := c.atom(name)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := xproto.GetProperty(c.conn, false, win, atom, xproto.GetPropertyTypeAny, 0, x11MaxPropertyLength).Reply()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s of window 0x%x: %w", name, win, err)
+	}
+	if reply.Format == 0 {
+		return nil, nil
+	}
+	return &x11Property{format: reply.Format, value: reply.Value}, nil
+}
+
+func (c *xgbConn) watchProperties(win xproto.Window) error {
+	err := xproto.ChangeWindowAttributesChecked(c.conn, win, xproto.CwEventMask, []uint32{xproto.EventMaskPropertyChange}).Check()
+	if err != nil {
+		return fmt.Errorf("failed to watch window 0x%x: %w", win, err)
+	}
+	return nil
+}
+
+func (c *xgbConn) propertyEvents() <-chan x11PropertyEvent {
+	c.eventsOnce.Do(func() {
+		c.events = make(chan x11PropertyEvent)
+		go c.readEvents()
+	})
+	return c.events
+}
+
+// readEvents forwards PropertyNotify events until the connection is closed
+func (c *xgbConn) readEvents() {
+	defer close(c.events)
+	for {
+		event, err := c.conn.WaitForEvent()
+		if event == nil && err == nil {
+			return
+		}
+		notify, ok := event.(xproto.PropertyNotifyEvent)
+		if !ok {
+			// X errors from watched windows that went away, and other events
+			continue
+		}
+		select {
+		case c.events <- x11PropertyEvent{window: notify.Window, atom: notify.Atom}:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// sendClientMessage sends a 32-bit client message to the root window, which
+// is how EWMH asks the window manager to act
+func (c *xgbConn) sendClientMessage(win xproto.Window, typ string, data ...uint32) error {
+	atom, err := c.atom(typ)
+	if err != nil {
+		return err
+	}
+	var values [5]uint32
+	copy(values[:], data)
+	event := xproto.ClientMessageEvent{
+		Format: 32,
+		Window: win,
+		Type:   atom,
+		Data:   xproto.ClientMessageDataUnionData32New(values[:]),
+	}
+	mask := uint32(xproto.EventMaskSubstructureNotify | xproto.EventMaskSubstructureRedirect)
+	if err := xproto.SendEventChecked(c.conn, false, c.rootWindow, mask, string(event.Bytes())).Check(); err != nil {
+		return fmt.Errorf("failed to send %s: %w", typ, err)
+	}
+	return nil
+}
+
+// Close closes the connection
+func (c *xgbConn) Close() {
+	close(c.done)
+	c.conn.Close()
+}
@@ -0,0 +1,524 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/jezek/xgb"
+	"github.com/jezek/xgb/xproto"
+)
+
+// fakeX11Root is the root window of fakeX11
+const fakeX11Root = xproto.Window(0x100)
+
+// fakeX11 stands in for the X server, holding properties per window and
+// recording the client messages it receives
+type fakeX11 struct {
+	mu         sync.Mutex
+	properties map[xproto.Window]map[string]*x11Property
+	atoms      map[string]xproto.Atom
+	watched    map[xproto.Window]bool
+	events     chan x11PropertyEvent
+	messages   []string
+}
+
+func (x *fakeX11) root() xproto.Window { return fakeX11Root }
+
+func (x *fakeX11) property(win xproto.Window, name string) (*x11Property, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	properties, ok := x.properties[win]
+	if !ok {
+		return nil, fmt.Errorf("BadWindow: 0x%x", uint32(win))
+	}
+	return properties[name], nil
+}
+
+func (x *fakeX11) atom(name string) (xproto.Atom, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.atoms == nil {
+		x.atoms = make(map[string]xproto.Atom)
+	}
+	if _, ok := x.atoms[name]; !ok {
+		x.atoms[name] = xproto.Atom(100 + len(x.atoms))
+	}
+	return x.atoms[name], nil
+}
+
+func (x *fakeX11) watchProperties(win xproto.Window) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.watched == nil {
+		x.watched = make(map[xproto.Window]bool)
+	}
+	x.watched[win] = true
+	return nil
+}
+
+func (x *fakeX11) propertyEvents() <-chan x11PropertyEvent { return x.events }
+
+// sendClientMessage records the message as "type window data...", using atom names
+func (x *fakeX11) sendClientMessage(win xproto.Window, typ string, data ...uint32) error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	names := make(map[uint32]string)
+	for name, atom := range x.atoms {
+		names[uint32(atom)] = name
+	}
+
+	message := fmt.Sprintf("%s 0x%x", typ, uint32(win))
+	for _, value := range data {
+		if name, ok := names[value]; ok {
+			message += " " + name
+		} else {
+			message += fmt.Sprintf(" %d", value)
+		}
+	}
+	x.messages = append(x.messages, message)
+	return nil
+}
+
+func (x *fakeX11) Close() {}
+
+// set changes a property, notifying the watcher if the window is watched
+func (x *fakeX11) set(win xproto.Window, name string, prop *x11Property) {
+	atom, _ := x.atom(name)
+	x.mu.Lock()
+	if x.properties[win] == nil {
+		x.properties[win] = make(map[string]*x11Property)
+	}
+	x.properties[win][name] = prop
+	watched := x.watched[win]
+	x.mu.Unlock()
+
+	if watched {
+		x.events <- x11PropertyEvent{window: win, atom: atom}
+	}
+}
+
+// x11Cardinals builds a 32-bit property
+func x11Cardinals(values ...uint32) *x11Property {
+	value := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(value[4*i:], v)
+	}
+	return &x11Property{format: 32, value: value}
+}
+
+// x11Strings builds a property of NUL-terminated strings
+func x11Strings(values ...string) *x11Property {
+	return &x11Property{format: 8, value: []byte(strings.Join(values, "\x00") + "\x00")}
+}
+
+// x11ProviderWith returns a provider talking to server
+func x11ProviderWith(server *fakeX11) *X11Provider {
+	return &X11Provider{conn: func() (x11Conn, error) { return server, nil }}
+}
+
+func TestX11Provider_GetActiveWindow(t *testing.T) {
+	server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{
+		fakeX11Root: {
+			"_NET_ACTIVE_WINDOW": x11Cardinals(0x1a00007),
+			"_NET_DESKTOP_NAMES": x11Strings("web", "code"),
+		},
+		0x1a00007: {
+			"_NET_WM_NAME":    {format: 8, value: []byte("main.go — Vim")},
+			"WM_NAME":         {format: 8, value: []byte("main.go - Vim")},
+			"WM_CLASS":        x11Strings("gvim", "Gvim"),
+			"_NET_WM_PID":     x11Cardinals(4242),
+			"_NET_WM_DESKTOP": x11Cardinals(1),
+		},
+	}}
+
+	info, err := x11ProviderWith(server).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	desktop := 1
+	expected := &window.WindowInfo{
+		ID:        "0x1a00007",
+		Title:     "main.go — Vim",
+		Class:     "Gvim",
+		Instance:  "gvim",
+		PID:       4242,
+		Workspace: "code",
+		Pinned:    window.Bool(false),
+		X11:       &window.X11Details{WindowID: 0x1a00007, DesktopIndex: &desktop},
+	}
+	if !reflect.DeepEqual(info, expected) {
+		t.Errorf("GetActiveWindow() = %+v\nwant %+v", info, expected)
+	}
+}
+
+func TestX11Provider_OldClient(t *testing.T) {
+	// No EWMH properties on the window: fall back to WM_NAME and unnamed desktops
+	server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{
+		fakeX11Root: {"_NET_ACTIVE_WINDOW": x11Cardinals(0x400001)},
+		0x400001: {
+			"WM_NAME":         {format: 8, value: []byte("xterm")},
+			"WM_CLASS":        x11Strings("xterm", "XTerm"),
+			"_NET_WM_DESKTOP": x11Cardinals(2),
+		},
+	}}
+
+	info, err := x11ProviderWith(server).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Title != "xterm" || info.Class != "XTerm" || info.Workspace != "3" || info.PID != 0 {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestX11Provider_Sticky(t *testing.T) {
+	server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{
+		fakeX11Root: {"_NET_ACTIVE_WINDOW": x11Cardinals(0x400001)},
+		0x400001:    {"_NET_WM_DESKTOP": x11Cardinals(x11AllDesktops)},
+	}}
+
+	info, err := x11ProviderWith(server).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.Pinned == nil || !*info.Pinned || info.Workspace != "" || info.X11.DesktopIndex != nil {
+		t.Errorf("window on all desktops = %+v, want pinned without a workspace", info)
+	}
+}
+
+func TestX11Provider_NoActiveWindow(t *testing.T) {
+	tests := []struct {
+		name string
+		root map[string]*x11Property
+		want string
+	}{
+		{"no EWMH window manager", map[string]*x11Property{}, "EWMH"},
+		{"nothing focused", map[string]*x11Property{"_NET_ACTIVE_WINDOW": x11Cardinals(0)}, "no active window"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{fakeX11Root: tt.root}}
+			_, err := x11ProviderWith(server).GetActiveWindow()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetActiveWindow() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestX11Provider_ListWindows(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+	}{
+		{"client list", "_NET_CLIENT_LIST"},
+		{"stacking order only", "_NET_CLIENT_LIST_STACKING"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 0x3 is listed but already gone
+			server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{
+				fakeX11Root: {tt.list: x11Cardinals(0x1, 0x2, 0x3)},
+				0x1:         {"WM_CLASS": x11Strings("firefox", "firefox")},
+				0x2:         {"WM_CLASS": x11Strings("xterm", "XTerm")},
+			}}
+
+			windows, err := x11ProviderWith(server).ListWindows()
+			if err != nil {
+				t.Fatalf("ListWindows() error: %v", err)
+			}
+			var classes []string
+			for _, w := range windows {
+				classes = append(classes, w.Class)
+			}
+			if !reflect.DeepEqual(classes, []string{"firefox", "XTerm"}) {
+				t.Errorf("ListWindows() classes = %q", classes)
+			}
+		})
+	}
+}
+
+func TestX11Provider_ListWindowsWithoutEWMH(t *testing.T) {
+	server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{fakeX11Root: {}}}
+	if _, err := x11ProviderWith(server).ListWindows(); err == nil || !strings.Contains(err.Error(), "_NET_CLIENT_LIST") {
+		t.Errorf("ListWindows() error = %v, want _NET_CLIENT_LIST mentioned", err)
+	}
+}
+
+func TestX11Provider_Watch(t *testing.T) {
+	server := &fakeX11{
+		properties: map[xproto.Window]map[string]*x11Property{
+			fakeX11Root: {"_NET_ACTIVE_WINDOW": x11Cardinals(0x1)},
+			0x1:         {"_NET_WM_NAME": {format: 8, value: []byte("one")}},
+			0x2:         {"_NET_WM_NAME": {format: 8, value: []byte("two")}},
+		},
+		events: make(chan x11PropertyEvent, 8),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := x11ProviderWith(server).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	next := func() string {
+		event := <-events
+		if event.Window == nil {
+			return "<none>"
+		}
+		return event.Window.Title
+	}
+
+	if title := next(); title != "one" {
+		t.Fatalf("first event title = %q, want one", title)
+	}
+
+	// A retitle of the active window is reported
+	server.set(0x1, "_NET_WM_NAME", &x11Property{format: 8, value: []byte("one, renamed")})
+	if title := next(); title != "one, renamed" {
+		t.Errorf("after retitle got %q", title)
+	}
+
+	server.set(fakeX11Root, "_NET_ACTIVE_WINDOW", x11Cardinals(0x2))
+	if title := next(); title != "two" {
+		t.Errorf("after focus change got %q", title)
+	}
+
+	// The previously active window is still watched, but no longer reported
+	server.set(0x1, "_NET_WM_NAME", &x11Property{format: 8, value: []byte("ignored")})
+	server.set(fakeX11Root, "_NET_ACTIVE_WINDOW", x11Cardinals(0))
+	if title := next(); title != "<none>" {
+		t.Errorf("after focus loss got %q", title)
+	}
+
+	cancel()
+	if _, ok := <-events; ok {
+		t.Error("expected the event channel to close")
+	}
+}
+
+func TestX11Provider_Actions(t *testing.T) {
+	tests := []struct {
+		name   string
+		action func(x *X11Provider) error
+		want   []string
+	}{
+		{
+			name:   "focus",
+			action: func(x *X11Provider) error { return x.FocusWindow("0x1a00007") },
+			want:   []string{"_NET_ACTIVE_WINDOW 0x1a00007 2 0"},
+		},
+		{
+			name:   "close by decimal ID",
+			action: func(x *X11Provider) error { return x.CloseWindow("27262983") },
+			want:   []string{"_NET_CLOSE_WINDOW 0x1a00007 0 2"},
+		},
+		{
+			name:   "fullscreen",
+			action: func(x *X11Provider) error { return x.ToggleFullscreen("0x1a00007") },
+			want:   []string{"_NET_WM_STATE 0x1a00007 2 _NET_WM_STATE_FULLSCREEN 0 2"},
+		},
+		{
+			name:   "maximize",
+			action: func(x *X11Provider) error { return x.ToggleMaximize("0x1a00007") },
+			want:   []string{"_NET_WM_STATE 0x1a00007 2 _NET_WM_STATE_MAXIMIZED_VERT _NET_WM_STATE_MAXIMIZED_HORZ 2"},
+		},
+		{
+			name:   "move to named desktop and follow",
+			action: func(x *X11Provider) error { return x.MoveWindowToWorkspace("0x1a00007", "code") },
+			want: []string{
+				"_NET_WM_DESKTOP 0x1a00007 1 2",
+				"_NET_CURRENT_DESKTOP 0x100 1 0",
+				"_NET_ACTIVE_WINDOW 0x1a00007 2 0",
+			},
+		},
+		{
+			name:   "move silently by number",
+			action: func(x *X11Provider) error { return x.MoveWindowToWorkspaceSilent("0x1a00007", "3") },
+			want:   []string{"_NET_WM_DESKTOP 0x1a00007 2 2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{fakeX11Root: {
+				"_NET_DESKTOP_NAMES":      x11Strings("web", "code"),
+				"_NET_NUMBER_OF_DESKTOPS": x11Cardinals(4),
+			}}}
+			if err := tt.action(x11ProviderWith(server)); err != nil {
+				t.Fatalf("action error: %v", err)
+			}
+			if !reflect.DeepEqual(server.messages, tt.want) {
+				t.Errorf("client messages = %q\nwant %q", server.messages, tt.want)
+			}
+		})
+	}
+}
+
+func TestX11Provider_ActionErrors(t *testing.T) {
+	server := &fakeX11{properties: map[xproto.Window]map[string]*x11Property{fakeX11Root: {
+		"_NET_NUMBER_OF_DESKTOPS": x11Cardinals(4),
+	}}}
+	provider := x11ProviderWith(server)
+
+	if err := provider.FocusWindow("firefox"); err == nil {
+		t.Error("expected an error for a non-numeric window ID")
+	}
+	if err := provider.MoveWindowToWorkspace("0x1", "9"); err == nil || !strings.Contains(err.Error(), "4 desktops") {
+		t.Errorf("MoveWindowToWorkspace() error = %v, want the desktop count mentioned", err)
+	}
+	if len(server.messages) != 0 {
+		t.Errorf("no messages should be sent, got %q", server.messages)
+	}
+}
+
+// startXvfb runs a private Xvfb for the test and returns its display, skipping
+// the test when Xvfb isn't installed
+func startXvfb(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("Xvfb"); err != nil {
+		t.Skip("Xvfb not installed")
+	}
+
+	// Xvfb picks a free display and writes its number to the pipe
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	cmd := exec.Command("Xvfb", "-displayfd", "3", "-nolisten", "tcp")
+	cmd.ExtraFiles = []*os.File{writer}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start Xvfb: %v", err)
+	}
+	writer.Close()
+	t.Cleanup(func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	})
+
+	number, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Xvfb didn't report its display: %v", err)
+	}
+	return ":" + strings.TrimSpace(number)
+}
+
+// xvfbClient is a raw X connection playing window manager in Xvfb tests
+type xvfbClient struct {
+	t    *testing.T
+	conn *xgb.Conn
+	root xproto.Window
+}
+
+func newXvfbClient(t *testing.T, display string) *xvfbClient {
+	conn, err := xgb.NewConnDisplay(display)
+	if err != nil {
+		t.Fatalf("failed to connect to Xvfb: %v", err)
+	}
+	t.Cleanup(conn.Close)
+	return &xvfbClient{t: t, conn: conn, root: xproto.Setup(conn).DefaultScreen(conn).Root}
+}
+
+func (c *xvfbClient) atom(name string) xproto.Atom {
+	reply, err := xproto.InternAtom(c.conn, false, uint16(len(name)), name).Reply()
+	if err != nil {
+		c.t.Fatalf("InternAtom(%s): %v", name, err)
+	}
+	return reply.Atom
+}
+
+// createWindow makes an unmapped window to hang properties on
+func (c *xvfbClient) createWindow() xproto.Window {
+	win, err := xproto.NewWindowId(c.conn)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	err = xproto.CreateWindowChecked(c.conn, 0, win, c.root, 0, 0, 100, 100, 0,
+		xproto.WindowClassInputOutput, 0, 0, nil).Check()
+	if err != nil {
+		c.t.Fatalf("CreateWindow: %v", err)
+	}
+	return win
+}
+
+// setProperty replaces a property of win with prop, typed typ
+func (c *xvfbClient) setProperty(win xproto.Window, name, typ string, prop *x11Property) {
+	length := uint32(len(prop.value)) / uint32(prop.format/8)
+	err := xproto.ChangePropertyChecked(c.conn, xproto.PropModeReplace, win, c.atom(name), c.atom(typ),
+		prop.format, length, prop.value).Check()
+	if err != nil {
+		c.t.Fatalf("ChangeProperty(%s): %v", name, err)
+	}
+}
+
+func TestX11Provider_Xvfb(t *testing.T) {
+	display := startXvfb(t)
+	t.Setenv("DISPLAY", display)
+	wm := newXvfbClient(t, display)
+
+	win := wm.createWindow()
+	wm.setProperty(win, "_NET_WM_NAME", "UTF8_STRING", &x11Property{format: 8, value: []byte("Terminal")})
+	wm.setProperty(win, "WM_CLASS", "STRING", x11Strings("xfce4-terminal", "Xfce4-terminal"))
+	wm.setProperty(win, "_NET_WM_PID", "CARDINAL", x11Cardinals(321))
+	wm.setProperty(win, "_NET_WM_DESKTOP", "CARDINAL", x11Cardinals(0))
+	wm.setProperty(wm.root, "_NET_DESKTOP_NAMES", "UTF8_STRING", x11Strings("Main"))
+	wm.setProperty(wm.root, "_NET_ACTIVE_WINDOW", "WINDOW", x11Cardinals(uint32(win)))
+
+	info, err := (&X11Provider{}).GetActiveWindow()
+	if err != nil {
+		t.Fatalf("GetActiveWindow() error: %v", err)
+	}
+	if info.X11.WindowID != uint32(win) || info.Title != "Terminal" || info.Class != "Xfce4-terminal" ||
+		info.Instance != "xfce4-terminal" || info.PID != 321 || info.Workspace != "Main" {
+		t.Errorf("unexpected window info: %+v", info)
+	}
+}
+
+func TestX11Provider_WatchXvfb(t *testing.T) {
+	display := startXvfb(t)
+	t.Setenv("DISPLAY", display)
+	wm := newXvfbClient(t, display)
+
+	first, second := wm.createWindow(), wm.createWindow()
+	wm.setProperty(first, "_NET_WM_NAME", "UTF8_STRING", &x11Property{format: 8, value: []byte("first")})
+	wm.setProperty(second, "_NET_WM_NAME", "UTF8_STRING", &x11Property{format: 8, value: []byte("second")})
+	wm.setProperty(wm.root, "_NET_ACTIVE_WINDOW", "WINDOW", x11Cardinals(uint32(first)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := (&X11Provider{}).Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error: %v", err)
+	}
+	expect := func(title string) {
+		t.Helper()
+		select {
+		case event := <-events:
+			if event.Window == nil || event.Window.Title != title {
+				t.Fatalf("event = %+v, want %q", event.Window, title)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for %q", title)
+		}
+	}
+
+	expect("first")
+	wm.setProperty(first, "_NET_WM_NAME", "UTF8_STRING", &x11Property{format: 8, value: []byte("first, renamed")})
+	expect("first, renamed")
+	wm.setProperty(wm.root, "_NET_ACTIVE_WINDOW", "WINDOW", x11Cardinals(uint32(second)))
+	expect("second")
+}
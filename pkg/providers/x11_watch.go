@@ -0,0 +1,129 @@
+package providers
+
+import (
+	"context"
+
+	"github.com/alde/yawi/pkg/window"
+	"github.com/jezek/xgb/xproto"
+)
+
+// x11WatchedProperties are the active window's properties that change what
+// watch reports
+var x11WatchedProperties = []string{"_NET_WM_NAME", "WM_NAME", "WM_CLASS", "_NET_WM_DESKTOP"}
+
+// x11Watch tracks the active window from PropertyNotify events
+type x11Watch struct {
+	conn x11Conn
+	// active is the window whose properties are reported
+	active     xproto.Window
+	activeAtom xproto.Atom
+	watched    map[xproto.Atom]bool
+}
+
+// Watch reports active window changes. The window manager announces focus
+// changes by updating _NET_ACTIVE_WINDOW on the root window; title and
+// desktop changes show up as property changes on the active window.
+func (x *X11Provider) Watch(ctx context.Context) (<-chan window.Event, error) {
+	conn, err := x.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := newX11Watch(conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	out := make(chan window.Event)
+	go func() {
+		defer close(out)
+		defer conn.Close()
+		w.run(ctx, out)
+	}()
+	return out, nil
+}
+
+// newX11Watch subscribes to the root window before reading the active window,
+// so no change goes unnoticed
+func newX11Watch(conn x11Conn) (*x11Watch, error) {
+	w := &x11Watch{conn: conn, watched: make(map[xproto.Atom]bool)}
+	for _, name := range x11WatchedProperties {
+		atom, err := conn.atom(name)
+		if err != nil {
+			return nil, err
+		}
+		w.watched[atom] = true
+	}
+
+	atom, err := conn.atom("_NET_ACTIVE_WINDOW")
+	if err != nil {
+		return nil, err
+	}
+	w.activeAtom = atom
+
+	if err := conn.watchProperties(conn.root()); err != nil {
+		return nil, err
+	}
+	active, err := x11ActiveWindow(conn)
+	if err != nil {
+		return nil, err
+	}
+	w.follow(active)
+	return w, nil
+}
+
+// follow makes win the reported window, subscribing to its property changes
+func (w *x11Watch) follow(win xproto.Window) {
+	if win != 0 && win != w.active {
+		// A window that is already gone simply reports no info below
+		w.conn.watchProperties(win)
+	}
+	w.active = win
+}
+
+// window reads the active window, nil when nothing has focus or it went away
+func (w *x11Watch) window() *window.WindowInfo {
+	if w.active == 0 {
+		return nil
+	}
+	info, err := x11WindowInfo(w.conn, w.active)
+	if err != nil {
+		return nil
+	}
+	return info
+}
+
+// run emits the active window on every relevant property change
+func (w *x11Watch) run(ctx context.Context, out chan<- window.Event) {
+	emitter := newWatchEmitter(ctx, out, "")
+	if !emitter.setWindow(w.window()) {
+		return
+	}
+
+	events := w.conn.propertyEvents()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			switch {
+			case event.window == w.conn.root() && event.atom == w.activeAtom:
+				active, err := x11ActiveWindow(w.conn)
+				if err != nil {
+					active = 0
+				}
+				w.follow(active)
+			case event.window == w.active && w.watched[event.atom]:
+			default:
+				continue
+			}
+			if !emitter.setWindow(w.window()) {
+				return
+			}
+		}
+	}
+}
@@ -1,13 +1,61 @@
 package window
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // WindowInfo represents information about a window across different compositors
 type WindowInfo struct {
+	// ID identifies the window to actions such as focus or close; its format is compositor specific
+	ID        string `json:"id,omitempty"`
 	Title     string `json:"title"`
 	Class     string `json:"class"`
+	Instance  string `json:"instance,omitempty"`
+	AppID     string `json:"app_id,omitempty"`
 	PID       int    `json:"pid"`
 	Workspace string `json:"workspace"`
+	Monitor   string `json:"monitor,omitempty"`
+
+	// Optional details, only filled in by compositors that report them. The
+	// flags are nil when unknown, so false means the compositor said so.
+	Geometry   *Geometry `json:"geometry,omitempty"`
+	Floating   *bool     `json:"floating,omitempty"`
+	Fullscreen *bool     `json:"fullscreen,omitempty"`
+	Pinned     *bool     `json:"pinned,omitempty"`
+	XWayland   *bool     `json:"xwayland,omitempty"`
+
+	// Compositor specific details
+	Hyprland *HyprlandDetails `json:"hyprland,omitempty"`
+	Sway     *SwayDetails     `json:"sway,omitempty"`
+	GNOME    *GNOMEDetails    `json:"gnome,omitempty"`
+	KDE      *KDEDetails      `json:"kde,omitempty"`
+	X11      *X11Details      `json:"x11,omitempty"`
+	Toplevel *ToplevelDetails `json:"toplevel,omitempty"`
+}
+
+// Bool returns a pointer to v, for setting WindowInfo's optional flags
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Geometry is a window's position and size in layout coordinates
+type Geometry struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// HyprlandDetails holds the window fields only Hyprland reports
+type HyprlandDetails struct {
+	Address        string   `json:"address"`
+	InitialClass   string   `json:"initial_class,omitempty"`
+	InitialTitle   string   `json:"initial_title,omitempty"`
+	FocusHistoryID int      `json:"focus_history_id"`
+	Grouped        []string `json:"grouped,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Swallowing     string   `json:"swallowing,omitempty"`
 }
 
 // String returns a friendly string representation of the window
@@ -18,11 +66,223 @@ func (w WindowInfo) String() string {
 	return fmt.Sprintf("🪟 %s (%s)", w.Title, w.Class)
 }
 
+// WorkspaceInfo describes a workspace (or virtual desktop)
+type WorkspaceInfo struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Monitor string `json:"monitor,omitempty"`
+	Windows int    `json:"windows"`
+	Special bool   `json:"special"`
+	Visible bool   `json:"visible"`
+	Active  bool   `json:"active"`
+}
+
+// MonitorInfo describes a connected output
+type MonitorInfo struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Description     string  `json:"description,omitempty"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	Scale           float64 `json:"scale"`
+	X               int     `json:"x"`
+	Y               int     `json:"y"`
+	Focused         bool    `json:"focused"`
+	ActiveWorkspace string  `json:"active_workspace,omitempty"`
+}
+
+// SwayDetails holds the window fields only Sway (and i3) report
+type SwayDetails struct {
+	// Shell is xdg_shell for native Wayland windows and xwayland for X11 ones
+	Shell       string   `json:"shell,omitempty"`
+	InhibitIdle bool     `json:"inhibit_idle"`
+	Marks       []string `json:"marks,omitempty"`
+}
+
+// GNOMEDetails holds the window fields only GNOME Shell reports
+type GNOMEDetails struct {
+	// WindowID is Mutter's window ID, as used by GNOME extensions
+	WindowID uint64 `json:"window_id"`
+	// WorkspaceIndex and MonitorIndex are zero-based; nil when the backend didn't say
+	WorkspaceIndex *int `json:"workspace_index,omitempty"`
+	MonitorIndex   *int `json:"monitor_index,omitempty"`
+}
+
+// KDEDetails holds the virtual desktop and Activity context KDE Plasma reports
+type KDEDetails struct {
+	// DesktopID is KWin's virtual desktop ID, a UUID on current Plasma versions
+	DesktopID   string `json:"desktop_id,omitempty"`
+	DesktopName string `json:"desktop_name,omitempty"`
+	// ActivityID and ActivityName describe the current Activity
+	ActivityID   string `json:"activity_id,omitempty"`
+	ActivityName string `json:"activity_name,omitempty"`
+	// Activities lists the Activities the window belongs to; empty means all of them
+	Activities []string `json:"activities,omitempty"`
+}
+
+// X11Details holds the window fields only X11 window managers report
+type X11Details struct {
+	// WindowID is the X window ID, which WindowInfo.ID shows in hex
+	WindowID uint32 `json:"window_id"`
+	// DesktopIndex is the zero-based _NET_WM_DESKTOP; nil for windows on every desktop
+	DesktopIndex *int `json:"desktop_index,omitempty"`
+}
+
+// ToplevelDetails holds the window fields the Wayland foreign toplevel protocols report
+type ToplevelDetails struct {
+	// Identifier is the stable ext_foreign_toplevel_list_v1 identifier, where offered
+	Identifier string `json:"identifier,omitempty"`
+	Maximized  bool   `json:"maximized"`
+	Minimized  bool   `json:"minimized"`
+}
+
+// ActivityInfo describes a KDE Plasma Activity
+type ActivityInfo struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Icon    string `json:"icon,omitempty"`
+	Running bool   `json:"running"`
+	Current bool   `json:"current"`
+}
+
 // Provider defines the interface for getting window information from different compositors
 type Provider interface {
 	// GetActiveWindow returns information about the currently active window
 	GetActiveWindow() (*WindowInfo, error)
-	
+
 	// Name returns the human-readable name of this provider
 	Name() string
-}
\ No newline at end of file
+}
+
+// Lister is implemented by providers that can enumerate every open window
+type Lister interface {
+	// ListWindows returns all mapped windows, not just the active one
+	ListWindows() ([]*WindowInfo, error)
+}
+
+// WorkspaceLister is implemented by providers that know about workspaces
+type WorkspaceLister interface {
+	// ListWorkspaces returns every existing workspace
+	ListWorkspaces() ([]*WorkspaceInfo, error)
+
+	// ActiveWorkspace returns the focused workspace
+	ActiveWorkspace() (*WorkspaceInfo, error)
+}
+
+// ActivityLister is implemented by providers with KDE-style Activities
+type ActivityLister interface {
+	// ListActivities returns every Activity, running or not
+	ListActivities() ([]*ActivityInfo, error)
+
+	// CurrentActivity returns the Activity in use
+	CurrentActivity() (*ActivityInfo, error)
+}
+
+// MonitorLister is implemented by providers that can enumerate outputs
+type MonitorLister interface {
+	ListMonitors() ([]*MonitorInfo, error)
+}
+
+// Controller is implemented by providers that can act on windows. Windows are
+// addressed by the ID reported in WindowInfo.
+type Controller interface {
+	// FocusWindow gives the window keyboard focus, switching workspace if needed
+	FocusWindow(id string) error
+
+	// CloseWindow asks the window to close
+	CloseWindow(id string) error
+
+	// MoveWindowToWorkspace sends the window to another workspace and follows it there
+	MoveWindowToWorkspace(id, workspace string) error
+
+	// ToggleFloating switches the window between tiled and floating
+	ToggleFloating(id string) error
+
+	// ToggleFullscreen switches the window in and out of fullscreen
+	ToggleFullscreen(id string) error
+}
+
+// SilentMover is implemented by providers that can move a window to another
+// workspace without following it there
+type SilentMover interface {
+	MoveWindowToWorkspaceSilent(id, workspace string) error
+}
+
+// Pinner is implemented by providers that can pin a window to all workspaces
+type Pinner interface {
+	TogglePin(id string) error
+}
+
+// Minimizer is implemented by providers that can minimize (iconify) a window
+type Minimizer interface {
+	MinimizeWindow(id string) error
+}
+
+// Maximizer is implemented by providers that can maximize windows
+type Maximizer interface {
+	ToggleMaximize(id string) error
+}
+
+// OutputMover is implemented by providers that can move a window to another monitor
+type OutputMover interface {
+	MoveWindowToOutput(id, output string) error
+}
+
+// Scratchpad is implemented by providers with a scratchpad of hidden windows
+type Scratchpad interface {
+	// ShowScratchpad toggles the given scratchpad window, or cycles through
+	// the scratchpad when id is empty
+	ShowScratchpad(id string) error
+}
+
+// Marker is implemented by providers supporting named window marks
+type Marker interface {
+	// ListMarks returns every mark currently set
+	ListMarks() ([]string, error)
+
+	// AddMark puts mark on the window, moving it away from any other window
+	AddMark(id, mark string) error
+
+	// RemoveMark takes mark off the window
+	RemoveMark(id, mark string) error
+
+	// FocusMark focuses the window carrying mark
+	FocusMark(mark string) error
+}
+
+// Versioner is implemented by providers that can report the compositor's version
+type Versioner interface {
+	// CompositorVersion returns a human-readable version, e.g. "v0.41.2" or "1.9"
+	CompositorVersion() (string, error)
+}
+
+// ModeReporter is implemented by providers with modal keybindings, such as
+// Sway's binding modes and Hyprland's submaps
+type ModeReporter interface {
+	// BindingMode returns the active mode; the default mode is "default"
+	BindingMode() (string, error)
+}
+
+// Selector is implemented by providers that can address windows by their
+// properties. The returned selector is accepted wherever a window ID is.
+type Selector interface {
+	SelectWindows(appID, class, title string) (string, error)
+}
+
+// Event describes a change reported by a Watcher
+type Event struct {
+	// Window is the newly active window, or nil when nothing has focus
+	Window *WindowInfo `json:"window"`
+
+	// Mode is the active keybinding mode (Sway binding mode, Hyprland submap),
+	// empty if the provider doesn't track modes
+	Mode string `json:"mode,omitempty"`
+}
+
+// Watcher is implemented by providers that can push active window changes
+// instead of being polled
+type Watcher interface {
+	// Watch delivers an Event whenever the active window changes, starting
+	// with the current state. The channel is closed once ctx is cancelled.
+	Watch(ctx context.Context) (<-chan Event, error)
+}
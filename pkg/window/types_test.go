@@ -1,6 +1,8 @@
 package window
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 )
 
@@ -88,4 +90,18 @@ func TestWindowInfo_JSONMarshaling(t *testing.T) {
 	if window.Workspace != "workspace-1" {
 		t.Errorf("Expected Workspace to be 'workspace-1', got %q", window.Workspace)
 	}
-}
\ No newline at end of file
+}
+
+func TestWindowInfo_FlagsJSON(t *testing.T) {
+	// A tiled window on a compositor that reports floating, and no pinned state at all
+	data, err := json.Marshal(WindowInfo{Title: "nvim", Floating: Bool(false)})
+	if err != nil {
+		t.Fatalf("failed to marshal window: %v", err)
+	}
+	if !strings.Contains(string(data), `"floating":false`) {
+		t.Errorf("known false flag missing from %s", data)
+	}
+	if strings.Contains(string(data), `"pinned"`) {
+		t.Errorf("unknown flag present in %s", data)
+	}
+}